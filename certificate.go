@@ -0,0 +1,85 @@
+package paypalnvp
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// NewCertificateClient creates a new client authenticating with an API
+// certificate instead of a signature. certPEM and keyPEM may be the same
+// data, as in the cert_key_pem.txt file PayPal provides. For P12
+// certificates see NewCertificateClientFromP12.
+func NewCertificateClient(environment Environment, user string, password string, certPEM []byte, keyPEM []byte) (*Client, error) {
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load API certificate: %s", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	return NewClientWithCredentials(&http.Client{Transport: transport}, environment, Credentials{
+		User:        user,
+		Password:    password,
+		Certificate: true,
+	})
+}
+
+// NewCertificateClientFromFile creates a new client authenticating with the
+// API certificate in the given PEM files. keyFile may be empty when
+// certFile also holds the private key.
+func NewCertificateClientFromFile(environment Environment, user string, password string, certFile string, keyFile string) (*Client, error) {
+	if keyFile == "" {
+		keyFile = certFile
+	}
+
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCertificateClient(environment, user, password, certPEM, keyPEM)
+}
+
+// NewCertificateClientFromP12 creates a new client authenticating with the
+// API certificate and private key in the PKCS#12 data, decrypted with
+// p12Password. Only the legacy 3DES and RC2 encryption PayPal uses is
+// supported, so P12 files exported by OpenSSL 3 need the -legacy flag.
+func NewCertificateClientFromP12(environment Environment, user string, password string, p12 []byte, p12Password string) (*Client, error) {
+	blocks, err := pkcs12.ToPEM(p12, p12Password)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load API certificate: %s", err)
+	}
+
+	var certPEM []byte
+	for _, block := range blocks {
+		certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+	}
+
+	return NewCertificateClient(environment, user, password, certPEM, certPEM)
+}
+
+// NewCertificateClientFromP12File creates a new client authenticating with
+// the API certificate in the given P12 file, see
+// NewCertificateClientFromP12.
+func NewCertificateClientFromP12File(environment Environment, user string, password string, p12File string, p12Password string) (*Client, error) {
+	p12, err := ioutil.ReadFile(p12File)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCertificateClientFromP12(environment, user, password, p12, p12Password)
+}
@@ -0,0 +1,116 @@
+package paypalnvp_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+func NewCertificatePEM(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "paypal_api1.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unable to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unable to marshal key: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return append(certPEM, keyPEM...)
+}
+
+func TestCertificateClient(t *testing.T) {
+	t.Run("NewCertificateClient", func(t *testing.T) {
+		t.Run("CreatesClientWithoutSignature", func(t *testing.T) {
+			certPEM := NewCertificatePEM(t)
+			client, err := paypalnvp.NewCertificateClient(paypalnvp.Sandbox, "user", "password", certPEM, certPEM)
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !client.Credentials.Certificate || client.Credentials.Signature != "" {
+				t.Fatalf("Expected certificate credentials, got: %+v", client.Credentials)
+			}
+		})
+
+		t.Run("ReturnsErrorForInvalidCertificate", func(t *testing.T) {
+			_, err := paypalnvp.NewCertificateClient(paypalnvp.Sandbox, "user", "password", []byte("invalid"), []byte("invalid"))
+
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+
+	t.Run("NewCertificateClientFromFile", func(t *testing.T) {
+		t.Run("LoadsCombinedPEMFile", func(t *testing.T) {
+			directory, _ := ioutil.TempDir("", "paypalnvp")
+			defer os.RemoveAll(directory)
+
+			certFile := filepath.Join(directory, "cert_key_pem.txt")
+			ioutil.WriteFile(certFile, NewCertificatePEM(t), 0600)
+
+			_, err := paypalnvp.NewCertificateClientFromFile(paypalnvp.Live, "user", "password", certFile, "")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForMissingFile", func(t *testing.T) {
+			_, err := paypalnvp.NewCertificateClientFromFile(paypalnvp.Live, "user", "password", "missing.pem", "")
+
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+
+	t.Run("NewCertificateClientFromP12File", func(t *testing.T) {
+		t.Run("LoadsP12File", func(t *testing.T) {
+			client, err := paypalnvp.NewCertificateClientFromP12File(paypalnvp.Live, "user", "password", "testdata/cert.p12", "secret")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !client.Credentials.Certificate || client.Credentials.Signature != "" {
+				t.Fatalf("Expected certificate credentials, got: %+v", client.Credentials)
+			}
+		})
+
+		t.Run("ReturnsErrorForIncorrectPassword", func(t *testing.T) {
+			_, err := paypalnvp.NewCertificateClientFromP12File(paypalnvp.Live, "user", "password", "testdata/cert.p12", "incorrect")
+
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+}
@@ -2,35 +2,55 @@ package paypalnvp
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/vidsy/go-paypalnvp/payload"
 )
 
 const (
-	baseAPIEndpoint                  = "https://%s.paypal.com/nvp"
-	sandboxAPISignatureRequestPrefix = "api-3t.sandbox"
-	apiSignatureRequestPrefix        = "api-3t"
+	baseAPIEndpoint       = "https://%s.paypal.com/nvp"
+	baseCheckoutURL       = "https://www.%spaypal.com/cgi-bin/webscr?cmd=_express-checkout&token=%s"
+	sandboxCheckoutPrefix = "sandbox."
 
 	//APIVersion version of the API to use.
-	APIVersion = "2.3"
-
-	// Sandsbox environment
-	Sandbox = "sandbox"
-
-	// Live environment
-	Live = "live"
+	APIVersion = "204.0"
 )
 
 type (
 	// Client struct used to interact with the NVP API.
 	Client struct {
 		client      TransportClient
-		environment string
-		User        string
-		Password    string
-		Signature   string
+		environment Environment
+		Credentials Credentials
+		RetryPolicy RetryPolicy
+
+		// TypedErrors makes Execute return an *APIError, along with the
+		// response, when PayPal reports a failure, and wrap other errors in
+		// a *TransportError, *DecodeError or *SerializationError.
+		TypedErrors bool
+
+		// Endpoint overrides the NVP endpoint URL derived from the
+		// environment and credentials, e.g. to target a proxy or a local
+		// fake server.
+		Endpoint string
+
+		// Logger receives a debug record of each request, with credentials
+		// redacted, and a record of each response or transport error.
+		Logger Logger
+
+		// RedactPII also redacts receivers, buyer email addresses and
+		// shipping addresses from logged requests.
+		RedactPII bool
+
+		// Interceptors wrap each call, in order, see Interceptor.
+		Interceptors []Interceptor
+
+		// Tracer starts a span around each call, see Tracer.
+		Tracer Tracer
 	}
 
 	// TransportClient interface for client providing HTTP transport
@@ -40,48 +60,211 @@ type (
 	}
 )
 
-// NewClient Creates a new client.
-func NewClient(client TransportClient, environment string, user string, password string, signature string) *Client {
+// NewClient Creates a new client authenticating with an API signature,
+// returning an error if the environment is unknown or the signature is
+// empty.
+func NewClient(client TransportClient, environment Environment, user string, password string, signature string) (*Client, error) {
+	return NewClientWithCredentials(client, environment, Credentials{
+		User:      user,
+		Password:  password,
+		Signature: signature,
+	})
+}
+
+// NewClientWithCredentials Creates a new client authenticating with the
+// given credentials, returning an error if the environment is unknown or
+// the credentials are invalid, see Credentials.Validate.
+func NewClientWithCredentials(client TransportClient, environment Environment, credentials Credentials) (*Client, error) {
+	if err := environment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := credentials.Validate(); err != nil {
+		return nil, err
+	}
+
 	if client == nil {
 		client = &http.Client{}
 	}
 
-	return &Client{client, environment, user, password, signature}
+	return &Client{
+		client:      client,
+		environment: environment,
+		Credentials: credentials,
+	}, nil
+}
+
+// WithCredentials returns a copy of the client authenticating with the
+// given credentials, e.g. to make a single call on behalf of another
+// account. It returns an error if the credentials are invalid.
+func (c Client) WithCredentials(credentials Credentials) (*Client, error) {
+	if err := credentials.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.Credentials = credentials
+	return &c, nil
 }
 
 // Execute performs the NVP request and returns the results.
 func (c Client) Execute(item payload.Serializer) (*Response, error) {
-	item.SetCredentials(
-		c.User,
-		c.Password,
-		c.Signature,
-		APIVersion,
-	)
+	return c.ExecuteContext(context.Background(), item)
+}
+
+// ExecuteContext validates the payload and performs the NVP request,
+// cancelling it if ctx is done before the response is received, and
+// returns the results. Invalid payloads are returned as the error of
+// Validate without any request being made.
+func (c Client) ExecuteContext(ctx context.Context, item payload.Serializer) (*Response, error) {
+	ctx, span := c.startSpan(ctx)
+	response, err := c.execute(ctx, span, item)
+	c.endSpan(span, response, err)
+
+	return response, err
+}
+
+// ExecuteInto performs the NVP request and decodes the results into out,
+// which should be a pointer to a method specific response such as
+// MassPayResponse.
+func (c Client) ExecuteInto(item payload.Serializer, out interface{}) error {
+	return c.ExecuteIntoContext(context.Background(), item, out)
+}
+
+// ExecuteIntoContext performs the NVP request with ctx and decodes the
+// results into out. Failed and partly decoded responses are decoded before
+// their error is returned.
+func (c Client) ExecuteIntoContext(ctx context.Context, item payload.Serializer, out interface{}) error {
+	response, err := c.ExecuteContext(ctx, item)
+	if response == nil {
+		return err
+	}
+
+	decodeErr := response.Decode(out)
+	if err != nil {
+		return err
+	}
+
+	return decodeErr
+}
+
+// ExpressCheckoutURL returns the URL to redirect the buyer to for the
+// express checkout token returned by SetExpressCheckout.
+func (c Client) ExpressCheckoutURL(token string) string {
+	prefix := sandboxCheckoutPrefix
+	if c.environment == Live {
+		prefix = ""
+	}
+
+	return fmt.Sprintf(baseCheckoutURL, prefix, url.QueryEscape(token))
+}
+
+func (c Client) execute(ctx context.Context, span Span, item payload.Serializer) (*Response, error) {
+	if err := item.Validate(); err != nil {
+		return nil, c.serializationError(err)
+	}
 
 	data, err := item.Serialize()
 	if err != nil {
-		return nil, err
+		return nil, c.serializationError(err)
+	}
+	c.Credentials.applyValues(data)
+	span.SetAttribute(AttributeMethod, data.Get(methodField))
+
+	call := &Call{
+		Payload: item,
+		Values:  data,
+		Header:  http.Header{},
 	}
 
-	httpResponse, err := c.perform(data)
+	response, err := c.intercept(ctx, call)
 	if err != nil {
-		return nil, err
+		return response, err
+	}
+
+	if c.TypedErrors && response != nil && response.IsFailure() {
+		return response, &APIError{Response: response}
+	}
+
+	return response, nil
+}
+
+func (c Client) serializationError(err error) error {
+	if !c.TypedErrors {
+		return err
+	}
+
+	return &SerializationError{Err: err}
+}
+
+func (c Client) requestError(response *Response, err error) error {
+	if !c.TypedErrors {
+		return err
+	}
+
+	if decodeFailure(err) {
+		return &DecodeError{Response: response, Err: err}
+	}
+
+	return &TransportError{Err: err}
+}
+
+func (c Client) executeWithRetry(ctx context.Context, call *Call) (*Response, error) {
+	data := call.Values
+	canRetry := c.RetryPolicy != nil && retryable(data)
+
+	for attempt := 1; ; attempt++ {
+		c.logRequest(ctx, data, attempt)
+		start := time.Now()
+		response, err := c.attempt(ctx, call)
+		c.logResponse(ctx, data, attempt, time.Since(start), response, err)
+		if response != nil {
+			response.Attempts = attempt
+		}
+
+		if !canRetry || ctx.Err() != nil {
+			return response, err
+		}
+
+		delay, retry := c.RetryPolicy.Retry(attempt, response, err)
+		if !retry {
+			return response, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
 	}
+}
 
-	response, err := NewResponse(httpResponse)
+func (c Client) attempt(ctx context.Context, call *Call) (*Response, error) {
+	httpResponse, err := c.perform(ctx, call)
 	if err != nil {
 		return nil, err
 	}
 
-	return response, nil
+	return NewResponse(httpResponse)
 }
 
-func (c Client) perform(serializedData string) (*http.Response, error) {
-	request, _ := http.NewRequest(
+func (c Client) perform(ctx context.Context, call *Call) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(
+		ctx,
 		"POST",
 		c.generateEndpoint(),
-		bytes.NewBuffer([]byte(serializedData)),
+		bytes.NewBufferString(call.Values.Encode()),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range call.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.Credentials.applyHeaders(request, time.Now())
 
 	response, err := c.client.Do(request)
 	if err != nil {
@@ -92,10 +275,13 @@ func (c Client) perform(serializedData string) (*http.Response, error) {
 }
 
 func (c Client) generateEndpoint() string {
-	endpointPrefix := sandboxAPISignatureRequestPrefix
-	if c.environment == Live {
-		endpointPrefix = apiSignatureRequestPrefix
+	if c.Endpoint != "" {
+		return c.Endpoint
+	}
+
+	if c.Credentials.Certificate {
+		return c.environment.CertificateEndpoint()
 	}
 
-	return fmt.Sprintf(baseAPIEndpoint, endpointPrefix)
+	return c.environment.SignatureEndpoint()
 }
@@ -2,9 +2,11 @@ package paypalnvp_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/vidsy/go-paypalnvp"
@@ -12,7 +14,8 @@ import (
 
 type (
 	SerializedDataMock struct {
-		mockSerialize func() (string, error)
+		mockValidate  func() error
+		mockSerialize func() (url.Values, error)
 	}
 
 	MockClient struct {
@@ -24,15 +27,20 @@ type (
 	}
 )
 
-func (sdm SerializedDataMock) Serialize() (string, error) {
-	if sdm.mockSerialize != nil {
-		return sdm.mockSerialize()
+func (sdm SerializedDataMock) Validate() error {
+	if sdm.mockValidate != nil {
+		return sdm.mockValidate()
 	}
 
-	return "SOME=Data", nil
+	return nil
 }
 
-func (sdm SerializedDataMock) SetCredentials(user string, password string, signature string, apiVersion string) {
+func (sdm SerializedDataMock) Serialize() (url.Values, error) {
+	if sdm.mockSerialize != nil {
+		return sdm.mockSerialize()
+	}
+
+	return url.Values{"SOME": {"Data"}}, nil
 }
 
 func (mrc MockReadCloser) Close() error {
@@ -61,18 +69,71 @@ func NewMockResponse(response []byte) (*http.Response, error) {
 func TestClient(t *testing.T) {
 	t.Run("NewClient", func(t *testing.T) {
 		t.Run("CreatesClientWithDefaultHTTPClient", func(t *testing.T) {
-			client := paypalnvp.NewClient(nil, "test", "user", "password", "signature")
+			client, _ := paypalnvp.NewClient(nil, paypalnvp.Sandbox, "user", "password", "signature")
 
 			if client == nil {
 				t.Fatalf("Expected new Client, got: %v", client)
 			}
 		})
+
+		t.Run("ReturnsErrorForUnknownEnvironment", func(t *testing.T) {
+			client, err := paypalnvp.NewClient(nil, "prod", "user", "password", "signature")
+
+			if err == nil || client != nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForEmptySignature", func(t *testing.T) {
+			client, err := paypalnvp.NewClient(nil, paypalnvp.Live, "user", "password", "")
+
+			if err == nil || client != nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+
+	t.Run("Endpoint", func(t *testing.T) {
+		signatureCredentials := paypalnvp.Credentials{User: "user", Password: "password", Signature: "signature"}
+		certificateCredentials := paypalnvp.Credentials{User: "user", Password: "password", Certificate: true}
+		testCases := []struct {
+			name        string
+			environment paypalnvp.Environment
+			credentials paypalnvp.Credentials
+			endpoint    string
+			expected    string
+		}{
+			{"SandboxSignature", paypalnvp.Sandbox, signatureCredentials, "", "https://api-3t.sandbox.paypal.com/nvp"},
+			{"LiveSignature", paypalnvp.Live, signatureCredentials, "", "https://api-3t.paypal.com/nvp"},
+			{"SandboxCertificate", paypalnvp.Sandbox, certificateCredentials, "", "https://api.sandbox.paypal.com/nvp"},
+			{"LiveCertificate", paypalnvp.Live, certificateCredentials, "", "https://api.paypal.com/nvp"},
+			{"CustomEndpoint", paypalnvp.Live, signatureCredentials, "http://localhost:8080/nvp", "http://localhost:8080/nvp"},
+		}
+
+		for _, testCase := range testCases {
+			t.Run(testCase.name, func(t *testing.T) {
+				endpoint := ""
+				httpClient := MockClient{
+					MockDo: func(request *http.Request) (*http.Response, error) {
+						endpoint = request.URL.String()
+						return NewMockResponse(nil)
+					},
+				}
+				client, _ := paypalnvp.NewClientWithCredentials(httpClient, testCase.environment, testCase.credentials)
+				client.Endpoint = testCase.endpoint
+				client.Execute(SerializedDataMock{})
+
+				if endpoint != testCase.expected {
+					t.Fatalf("Expected endpoint to be '%s', got: '%s'", testCase.expected, endpoint)
+				}
+			})
+		}
 	})
 
 	t.Run(".Execute", func(t *testing.T) {
 		t.Run("PerformsRequestWithSerializedData", func(t *testing.T) {
 			httpClient := MockClient{}
-			client := paypalnvp.NewClient(httpClient, "test", "user", "password", "signature")
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
 			payload := SerializedDataMock{}
 			response, _ := client.Execute(payload)
 
@@ -83,10 +144,10 @@ func TestClient(t *testing.T) {
 
 		t.Run("ReturnsErrorOnSerializeError", func(t *testing.T) {
 			httpClient := MockClient{}
-			client := paypalnvp.NewClient(httpClient, "test", "user", "password", "signature")
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
 			payload := SerializedDataMock{
-				mockSerialize: func() (string, error) {
-					return "", errors.New("Serializer error")
+				mockSerialize: func() (url.Values, error) {
+					return nil, errors.New("Serializer error")
 				},
 			}
 			_, err := client.Execute(payload)
@@ -96,13 +157,35 @@ func TestClient(t *testing.T) {
 			}
 		})
 
+		t.Run("ReturnsValidationErrorWithoutRequest", func(t *testing.T) {
+			requested := false
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					requested = true
+					return NewMockResponse(nil)
+				},
+			}
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			validationError := errors.New("Validation error")
+			payload := SerializedDataMock{
+				mockValidate: func() error {
+					return validationError
+				},
+			}
+			_, err := client.Execute(payload)
+
+			if err != validationError || requested {
+				t.Fatalf("Expected validation error and no request, got: %v, %t", err, requested)
+			}
+		})
+
 		t.Run("ReturnsErrorOnClientRequestError", func(t *testing.T) {
 			httpClient := MockClient{
 				MockDo: func(request *http.Request) (*http.Response, error) {
 					return nil, errors.New("Client error")
 				},
 			}
-			client := paypalnvp.NewClient(httpClient, "test", "user", "password", "signature")
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
 			payload := SerializedDataMock{}
 			_, err := client.Execute(payload)
 
@@ -111,4 +194,66 @@ func TestClient(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run(".ExecuteContext", func(t *testing.T) {
+		t.Run("PassesContextToRequest", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					if err := request.Context().Err(); err != nil {
+						return nil, err
+					}
+
+					return NewMockResponse(nil)
+				},
+			}
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			_, err := client.ExecuteContext(ctx, SerializedDataMock{})
+
+			if err != context.Canceled {
+				t.Fatalf("Expected context.Canceled, got: %v", err)
+			}
+		})
+	})
+
+	t.Run(".ExecuteInto", func(t *testing.T) {
+		t.Run("DecodesIntoTypedResponse", func(t *testing.T) {
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					return NewMockResponse([]byte(`ACK=Success&CORRELATIONID=5be53331d9700&TOKEN=EC-123`))
+				},
+			}
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			response := paypalnvp.SetExpressCheckoutResponse{}
+			err := client.ExecuteInto(SerializedDataMock{}, &response)
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if response.Token != "EC-123" {
+				t.Fatalf("Expected Token to be 'EC-123', got: '%s'", response.Token)
+			}
+
+			if response.CorrelationID != "5be53331d9700" || response.StatusCode != 200 {
+				t.Fatalf("Expected common response fields to be set, got: '%s', %d", response.CorrelationID, response.StatusCode)
+			}
+		})
+
+		t.Run("ReturnsErrorOnClientRequestError", func(t *testing.T) {
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					return nil, errors.New("Client error")
+				},
+			}
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			err := client.ExecuteInto(SerializedDataMock{}, &paypalnvp.MassPayResponse{})
+
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
 }
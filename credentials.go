@@ -0,0 +1,124 @@
+package paypalnvp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AuthorizationHeader header carrying the permissions authorization
+	// for calls made with a PermissionToken.
+	AuthorizationHeader = "X-PAYPAL-AUTHORIZATION"
+
+	userField      = "USER"
+	passwordField  = "PWD"
+	signatureField = "SIGNATURE"
+	versionField   = "VERSION"
+	subjectField   = "SUBJECT"
+)
+
+type (
+	// Credentials used to authenticate requests.
+	//
+	// Signature credentials set User, Password and Signature. Certificate
+	// credentials set Certificate instead of Signature and rely on the
+	// TransportClient presenting the API certificate, see
+	// NewCertificateClient. Calls are
+	// made on behalf of another account either by setting Subject to its
+	// email address or payer ID, or by setting Permission to an access token
+	// the account granted through the Permissions service.
+	Credentials struct {
+		User       string
+		Password   string
+		Signature  string
+		Subject    string
+		Permission *PermissionToken
+
+		// Certificate authenticates with an API certificate rather than a
+		// signature, and sends requests to the certificate endpoints.
+		Certificate bool
+	}
+
+	// PermissionToken access token and secret granted by a third-party
+	// account through the Permissions service.
+	PermissionToken struct {
+		AccessToken string
+		TokenSecret string
+	}
+)
+
+// Validate checks that a signature is set, unless the credentials rely on
+// an API certificate.
+func (c Credentials) Validate() error {
+	if c.Certificate && c.Signature != "" {
+		return errors.New("Invalid credentials, expected no signature with certificate authentication")
+	}
+
+	if !c.Certificate && c.Signature == "" {
+		return errors.New("Invalid credentials, expected a signature or certificate authentication")
+	}
+
+	return nil
+}
+
+// Authorization returns the X-PAYPAL-AUTHORIZATION header value for a call
+// to endpoint made by the API user with password at timestamp. The
+// signature is an OAuth 1.0 HMAC-SHA1 signature keyed by the API password
+// and token secret.
+func (pt PermissionToken) Authorization(user string, password string, endpoint string, timestamp time.Time) string {
+	unixTimestamp := strconv.FormatInt(timestamp.Unix(), 10)
+	parameters := strings.Join([]string{
+		"oauth_consumer_key=" + oauthEscape(user),
+		"oauth_signature_method=HMAC-SHA1",
+		"oauth_timestamp=" + unixTimestamp,
+		"oauth_token=" + oauthEscape(pt.AccessToken),
+		"oauth_version=1.0",
+	}, "&")
+
+	baseString := strings.Join([]string{
+		http.MethodPost,
+		oauthEscape(endpoint),
+		oauthEscape(parameters),
+	}, "&")
+
+	mac := hmac.New(sha1.New, []byte(oauthEscape(password)+"&"+oauthEscape(pt.TokenSecret)))
+	mac.Write([]byte(baseString))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("token=%s,signature=%s,timestamp=%s", pt.AccessToken, signature, unixTimestamp)
+}
+
+func (c Credentials) applyValues(data url.Values) {
+	data.Set(userField, c.User)
+	data.Set(passwordField, c.Password)
+	data.Set(versionField, APIVersion)
+
+	if !c.Certificate {
+		data.Set(signatureField, c.Signature)
+	}
+
+	if c.Subject != "" && c.Permission == nil {
+		data.Set(subjectField, c.Subject)
+	}
+}
+
+func (c Credentials) applyHeaders(request *http.Request, timestamp time.Time) {
+	if c.Permission != nil {
+		request.Header.Set(
+			AuthorizationHeader,
+			c.Permission.Authorization(c.User, c.Password, request.URL.String(), timestamp),
+		)
+	}
+}
+
+func oauthEscape(value string) string {
+	return strings.Replace(url.QueryEscape(value), "+", "%20", -1)
+}
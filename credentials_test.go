@@ -0,0 +1,143 @@
+package paypalnvp_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+func NewCapturingClient(credentials paypalnvp.Credentials, captured **http.Request, body *url.Values) *paypalnvp.Client {
+	httpClient := MockClient{
+		MockDo: func(request *http.Request) (*http.Response, error) {
+			*captured = request
+			data, _ := ioutil.ReadAll(request.Body)
+			*body, _ = url.ParseQuery(string(data))
+
+			return NewMockResponse(nil)
+		},
+	}
+	client, _ := paypalnvp.NewClientWithCredentials(httpClient, paypalnvp.Sandbox, credentials)
+
+	return client
+}
+
+func TestCredentials(t *testing.T) {
+	t.Run("InjectsCredentialsIntoPayload", func(t *testing.T) {
+		var request *http.Request
+		body := url.Values{}
+		credentials := paypalnvp.Credentials{User: "user", Password: "password", Signature: "signature"}
+		NewCapturingClient(credentials, &request, &body).Execute(SerializedDataMock{})
+
+		expected := "PWD=password&SIGNATURE=signature&SOME=Data&USER=user&VERSION=204.0"
+		if body.Encode() != expected {
+			t.Fatalf("Expected body to be '%s', got: '%s'", expected, body.Encode())
+		}
+	})
+
+	t.Run("OmitsSignatureForCertificateCredentials", func(t *testing.T) {
+		var request *http.Request
+		body := url.Values{}
+		credentials := paypalnvp.Credentials{User: "user", Password: "password", Certificate: true}
+		NewCapturingClient(credentials, &request, &body).Execute(SerializedDataMock{})
+
+		if _, ok := body["SIGNATURE"]; ok {
+			t.Fatalf("Expected no SIGNATURE, got: '%s'", body.Get("SIGNATURE"))
+		}
+	})
+
+	t.Run("ReturnsErrorForSignatureWithCertificate", func(t *testing.T) {
+		credentials := paypalnvp.Credentials{User: "user", Password: "password", Signature: "signature", Certificate: true}
+
+		if _, err := paypalnvp.NewClientWithCredentials(nil, paypalnvp.Live, credentials); err == nil {
+			t.Fatalf("Expected an error, got: %v", err)
+		}
+	})
+
+	t.Run(".WithCredentials", func(t *testing.T) {
+		t.Run("UsesCredentialsOnCopy", func(t *testing.T) {
+			var request *http.Request
+			body := url.Values{}
+			client := NewCapturingClient(paypalnvp.Credentials{User: "user", Password: "password", Signature: "signature"}, &request, &body)
+			other, err := client.WithCredentials(paypalnvp.Credentials{User: "other", Password: "other", Signature: "other"})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			other.Execute(SerializedDataMock{})
+
+			if body.Get("USER") != "other" {
+				t.Fatalf("Expected USER to be 'other', got: '%s'", body.Get("USER"))
+			}
+
+			if client.Credentials.User != "user" {
+				t.Fatalf("Expected original client to keep USER 'user', got: '%s'", client.Credentials.User)
+			}
+		})
+
+		t.Run("ReturnsErrorForInvalidCredentials", func(t *testing.T) {
+			client, _ := paypalnvp.NewClient(nil, paypalnvp.Live, "user", "password", "signature")
+
+			if _, err := client.WithCredentials(paypalnvp.Credentials{User: "other", Password: "other"}); err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+
+	t.Run("SendsSubjectForThirdPartyCalls", func(t *testing.T) {
+		var request *http.Request
+		body := url.Values{}
+		credentials := paypalnvp.Credentials{User: "user", Password: "password", Signature: "signature", Subject: "merchant@example.com"}
+		NewCapturingClient(credentials, &request, &body).Execute(SerializedDataMock{})
+
+		if body.Get("SUBJECT") != "merchant@example.com" {
+			t.Fatalf("Expected SUBJECT to be 'merchant@example.com', got: '%s'", body.Get("SUBJECT"))
+		}
+
+		if request.Header.Get(paypalnvp.AuthorizationHeader) != "" {
+			t.Fatalf("Expected no authorization header, got: '%s'", request.Header.Get(paypalnvp.AuthorizationHeader))
+		}
+	})
+
+	t.Run("SendsAuthorizationHeaderForPermissionCalls", func(t *testing.T) {
+		var request *http.Request
+		body := url.Values{}
+		credentials := paypalnvp.Credentials{
+			User:       "user",
+			Password:   "password",
+			Signature:  "signature",
+			Subject:    "merchant@example.com",
+			Permission: &paypalnvp.PermissionToken{AccessToken: "ACCESS-TOKEN", TokenSecret: "secret"},
+		}
+		NewCapturingClient(credentials, &request, &body).Execute(SerializedDataMock{})
+
+		if !strings.HasPrefix(request.Header.Get(paypalnvp.AuthorizationHeader), "token=ACCESS-TOKEN,signature=") {
+			t.Fatalf("Expected authorization header, got: '%s'", request.Header.Get(paypalnvp.AuthorizationHeader))
+		}
+
+		if body.Get("SUBJECT") != "" {
+			t.Fatalf("Expected no SUBJECT, got: '%s'", body.Get("SUBJECT"))
+		}
+	})
+
+	t.Run("PermissionToken", func(t *testing.T) {
+		t.Run(".Authorization", func(t *testing.T) {
+			permission := paypalnvp.PermissionToken{AccessToken: "ACCESS-TOKEN", TokenSecret: "token~secret"}
+			authorization := permission.Authorization(
+				"api_user.example.com",
+				"pass word",
+				"https://api-3t.sandbox.paypal.com/nvp",
+				time.Unix(1300000000, 0),
+			)
+
+			expected := "token=ACCESS-TOKEN,signature=SIwBAF6aJ9FV347rM9xcDB+o/YY=,timestamp=1300000000"
+			if authorization != expected {
+				t.Fatalf("Expected Authorization() to be '%s', got: '%s'", expected, authorization)
+			}
+		})
+	})
+}
@@ -0,0 +1,148 @@
+// Package currency lists the currencies PayPal supports for payments, with
+// the decimal places and per-transaction maximum PayPal accepts for each.
+package currency
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// AUD Australian dollar.
+	AUD = "AUD"
+	// BRL Brazilian real, in-country PayPal accounts only.
+	BRL = "BRL"
+	// CAD Canadian dollar.
+	CAD = "CAD"
+	// CHF Swiss franc.
+	CHF = "CHF"
+	// CZK Czech koruna.
+	CZK = "CZK"
+	// DKK Danish krone.
+	DKK = "DKK"
+	// EUR Euro.
+	EUR = "EUR"
+	// GBP Pound sterling.
+	GBP = "GBP"
+	// HKD Hong Kong dollar.
+	HKD = "HKD"
+	// HUF Hungarian forint, no decimal places.
+	HUF = "HUF"
+	// ILS Israeli new shekel.
+	ILS = "ILS"
+	// JPY Japanese yen, no decimal places.
+	JPY = "JPY"
+	// MXN Mexican peso.
+	MXN = "MXN"
+	// MYR Malaysian ringgit, in-country PayPal accounts only.
+	MYR = "MYR"
+	// NOK Norwegian krone.
+	NOK = "NOK"
+	// NZD New Zealand dollar.
+	NZD = "NZD"
+	// PHP Philippine peso.
+	PHP = "PHP"
+	// PLN Polish złoty.
+	PLN = "PLN"
+	// SEK Swedish krona.
+	SEK = "SEK"
+	// SGD Singapore dollar.
+	SGD = "SGD"
+	// THB Thai baht.
+	THB = "THB"
+	// TWD New Taiwan dollar, no decimal places.
+	TWD = "TWD"
+	// USD United States dollar.
+	USD = "USD"
+
+	defaultDecimals = 2
+)
+
+type (
+	// Currency PayPal rules for a currency code.
+	Currency struct {
+		Code string
+
+		// Decimals number of decimal places PayPal accepts in amounts.
+		Decimals int
+
+		// MaxAmount maximum amount of a single transaction, in whole units
+		// of the currency.
+		MaxAmount int64
+	}
+
+	// UnsupportedError returned when PayPal does not support a currency
+	// code.
+	UnsupportedError struct {
+		Code string
+	}
+)
+
+var currencies = map[string]Currency{
+	AUD: {Code: AUD, Decimals: 2, MaxAmount: 12500},
+	BRL: {Code: BRL, Decimals: 2, MaxAmount: 20000},
+	CAD: {Code: CAD, Decimals: 2, MaxAmount: 12500},
+	CHF: {Code: CHF, Decimals: 2, MaxAmount: 13000},
+	CZK: {Code: CZK, Decimals: 2, MaxAmount: 240000},
+	DKK: {Code: DKK, Decimals: 2, MaxAmount: 60000},
+	EUR: {Code: EUR, Decimals: 2, MaxAmount: 8000},
+	GBP: {Code: GBP, Decimals: 2, MaxAmount: 5500},
+	HKD: {Code: HKD, Decimals: 2, MaxAmount: 60000},
+	HUF: {Code: HUF, Decimals: 0, MaxAmount: 2000000},
+	ILS: {Code: ILS, Decimals: 2, MaxAmount: 40000},
+	JPY: {Code: JPY, Decimals: 0, MaxAmount: 1000000},
+	MXN: {Code: MXN, Decimals: 2, MaxAmount: 110000},
+	MYR: {Code: MYR, Decimals: 2, MaxAmount: 40000},
+	NOK: {Code: NOK, Decimals: 2, MaxAmount: 70000},
+	NZD: {Code: NZD, Decimals: 2, MaxAmount: 15000},
+	PHP: {Code: PHP, Decimals: 2, MaxAmount: 500000},
+	PLN: {Code: PLN, Decimals: 2, MaxAmount: 32000},
+	SEK: {Code: SEK, Decimals: 2, MaxAmount: 80000},
+	SGD: {Code: SGD, Decimals: 2, MaxAmount: 16000},
+	THB: {Code: THB, Decimals: 2, MaxAmount: 360000},
+	TWD: {Code: TWD, Decimals: 0, MaxAmount: 330000},
+	USD: {Code: USD, Decimals: 2, MaxAmount: 10000},
+}
+
+// Lookup returns the rules for a currency code, and whether PayPal
+// supports it.
+func Lookup(code string) (Currency, bool) {
+	currency, ok := currencies[code]
+	return currency, ok
+}
+
+// Validate returns an UnsupportedError if PayPal does not support the
+// currency code.
+func Validate(code string) error {
+	if _, ok := currencies[code]; !ok {
+		return UnsupportedError{Code: code}
+	}
+
+	return nil
+}
+
+// Decimals number of decimal places PayPal accepts for the currency code,
+// two for unknown codes.
+func Decimals(code string) int {
+	if currency, ok := currencies[code]; ok {
+		return currency.Decimals
+	}
+
+	return defaultDecimals
+}
+
+// Codes returns the supported currency codes in alphabetical order.
+func Codes() []string {
+	codes := make([]string, 0, len(currencies))
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+// Error Formatted error string based on properties.
+func (e UnsupportedError) Error() string {
+	return fmt.Sprintf("Unsupported currency code '%s'", e.Code)
+}
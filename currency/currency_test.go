@@ -0,0 +1,52 @@
+package currency_test
+
+import (
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp/currency"
+)
+
+func TestCurrency(t *testing.T) {
+	t.Run("Lookup()", func(t *testing.T) {
+		t.Run("ReturnsRulesForSupportedCode", func(t *testing.T) {
+			jpy, ok := currency.Lookup(currency.JPY)
+
+			if !ok || jpy.Decimals != 0 || jpy.MaxAmount != 1000000 {
+				t.Fatalf("Expected JPY with 0 decimals and a maximum of 1000000, got: %+v", jpy)
+			}
+		})
+
+		t.Run("ReturnsFalseForUnsupportedCode", func(t *testing.T) {
+			if _, ok := currency.Lookup("XYZ"); ok {
+				t.Fatal("Expected XYZ to be unsupported")
+			}
+		})
+	})
+
+	t.Run("Validate()", func(t *testing.T) {
+		t.Run("ReturnsUnsupportedError", func(t *testing.T) {
+			err := currency.Validate("gbp")
+			if _, ok := err.(currency.UnsupportedError); !ok {
+				t.Fatalf("Expected UnsupportedError, got: %v", err)
+			}
+		})
+	})
+
+	t.Run("Decimals()", func(t *testing.T) {
+		t.Run("DefaultsToTwoForUnknownCodes", func(t *testing.T) {
+			if decimals := currency.Decimals(""); decimals != 2 {
+				t.Fatalf("Expected 2 decimals, got: %d", decimals)
+			}
+		})
+	})
+
+	t.Run("Codes()", func(t *testing.T) {
+		t.Run("ReturnsSortedCodes", func(t *testing.T) {
+			codes := currency.Codes()
+
+			if codes[0] != currency.AUD || codes[len(codes)-1] != currency.USD {
+				t.Fatalf("Expected codes from AUD to USD, got: %v", codes)
+			}
+		})
+	})
+}
@@ -0,0 +1,52 @@
+package paypalnvp
+
+import (
+	"fmt"
+)
+
+const (
+	signatureEndpointPrefix          = "api-3t"
+	sandboxSignatureEndpointPrefix   = "api-3t.sandbox"
+	certificateEndpointPrefix        = "api"
+	sandboxCertificateEndpointPrefix = "api.sandbox"
+
+	// Sandbox environment
+	Sandbox Environment = "sandbox"
+
+	// Live environment
+	Live Environment = "live"
+)
+
+type (
+	// Environment PayPal environment requests are sent to.
+	Environment string
+)
+
+// Validate returns an error if the environment is not Sandbox or Live.
+func (e Environment) Validate() error {
+	if e != Sandbox && e != Live {
+		return fmt.Errorf("Unknown environment '%s', expected '%s' or '%s'", e, Sandbox, Live)
+	}
+
+	return nil
+}
+
+// SignatureEndpoint NVP endpoint for requests authenticated with an API
+// signature.
+func (e Environment) SignatureEndpoint() string {
+	if e == Live {
+		return fmt.Sprintf(baseAPIEndpoint, signatureEndpointPrefix)
+	}
+
+	return fmt.Sprintf(baseAPIEndpoint, sandboxSignatureEndpointPrefix)
+}
+
+// CertificateEndpoint NVP endpoint for requests authenticated with an API
+// certificate.
+func (e Environment) CertificateEndpoint() string {
+	if e == Live {
+		return fmt.Sprintf(baseAPIEndpoint, certificateEndpointPrefix)
+	}
+
+	return fmt.Sprintf(baseAPIEndpoint, sandboxCertificateEndpointPrefix)
+}
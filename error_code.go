@@ -0,0 +1,143 @@
+package paypalnvp
+
+import (
+	"strconv"
+)
+
+const (
+	// CategoryUnknown the error code is not in the catalogue.
+	CategoryUnknown ErrorCategory = "unknown"
+
+	// CategoryAuthentication the credentials were rejected or lack
+	// permission for the call.
+	CategoryAuthentication ErrorCategory = "authentication"
+
+	// CategoryInvalidRequest a field of the request is missing or invalid.
+	CategoryInvalidRequest ErrorCategory = "invalid_request"
+
+	// CategoryInsufficientFunds the account balance does not cover the
+	// payment.
+	CategoryInsufficientFunds ErrorCategory = "insufficient_funds"
+
+	// CategoryInvalidReceiver a receiver is invalid or cannot receive the
+	// payment.
+	CategoryInvalidReceiver ErrorCategory = "invalid_receiver"
+
+	// CategoryRestricted the sending account is restricted from making the
+	// payment.
+	CategoryRestricted ErrorCategory = "restricted"
+
+	// CategoryDeclined the payment was declined by the processor or
+	// funding source.
+	CategoryDeclined ErrorCategory = "declined"
+
+	// CategoryDuplicateRequest the request duplicates one already
+	// processed.
+	CategoryDuplicateRequest ErrorCategory = "duplicate_request"
+
+	// CategoryMassPay MassPay error without a more specific category.
+	CategoryMassPay ErrorCategory = "masspay"
+
+	// CategoryInternal PayPal failed to process the request.
+	CategoryInternal ErrorCategory = "internal"
+
+	// FaultUnknown the error code is not in the catalogue.
+	FaultUnknown Fault = "unknown"
+
+	// FaultCaller the request, account or receiver needs changing before
+	// the call can succeed.
+	FaultCaller Fault = "caller"
+
+	// FaultPayPal PayPal failed to process a valid request.
+	FaultPayPal Fault = "paypal"
+
+	massPayRangeStart = 11200
+	massPayRangeEnd   = 11299
+)
+
+type (
+	// ErrorCategory broad reason for an NVP error code, for routing
+	// failures.
+	ErrorCategory string
+
+	// Fault party responsible for an NVP error code.
+	Fault string
+
+	errorCode struct {
+		category  ErrorCategory
+		retryable bool
+		fault     Fault
+	}
+)
+
+var errorCodes = map[string]errorCode{
+	"10001": {CategoryInternal, true, FaultPayPal},
+	"10002": {CategoryAuthentication, false, FaultCaller},
+	"10004": {CategoryInvalidRequest, false, FaultCaller},
+	"10007": {CategoryAuthentication, false, FaultCaller},
+	"10008": {CategoryAuthentication, false, FaultCaller},
+	"10009": {CategoryInvalidRequest, false, FaultCaller},
+	"10301": {CategoryRestricted, false, FaultCaller},
+	"10303": {CategoryRestricted, false, FaultCaller},
+	"10304": {CategoryRestricted, false, FaultCaller},
+	"10305": {CategoryRestricted, false, FaultCaller},
+	"10306": {CategoryRestricted, false, FaultCaller},
+	"10307": {CategoryRestricted, false, FaultCaller},
+	"10308": {CategoryInternal, false, FaultPayPal},
+	"10309": {CategoryInternal, true, FaultPayPal},
+	"10310": {CategoryInternal, true, FaultPayPal},
+	"10311": {CategoryInternal, true, FaultPayPal},
+	"10312": {CategoryInternal, true, FaultPayPal},
+	"10313": {CategoryInvalidReceiver, false, FaultCaller},
+	"10314": {CategoryInvalidRequest, false, FaultCaller},
+	"10317": {CategoryInvalidReceiver, false, FaultCaller},
+	"10320": {CategoryInternal, true, FaultPayPal},
+	"10321": {CategoryInsufficientFunds, false, FaultCaller},
+	"10327": {CategoryInvalidReceiver, false, FaultCaller},
+	"10410": {CategoryInvalidRequest, false, FaultCaller},
+	"10412": {CategoryDuplicateRequest, false, FaultCaller},
+	"10415": {CategoryDuplicateRequest, false, FaultCaller},
+	"10417": {CategoryDeclined, false, FaultCaller},
+	"10486": {CategoryDeclined, false, FaultCaller},
+	"11607": {CategoryDuplicateRequest, false, FaultCaller},
+	"15005": {CategoryDeclined, false, FaultCaller},
+	"81002": {CategoryInvalidRequest, false, FaultCaller},
+}
+
+var categorySentinels = map[ErrorCategory]error{
+	CategoryAuthentication:    ErrAuthentication,
+	CategoryInsufficientFunds: ErrInsufficientFunds,
+	CategoryInvalidReceiver:   ErrInvalidReceiver,
+	CategoryDuplicateRequest:  ErrDuplicateRequest,
+	CategoryInternal:          ErrInternal,
+}
+
+// Category broad reason for the error, CategoryUnknown if the code is not
+// in the catalogue.
+func (r ResponseError) Category() ErrorCategory {
+	return r.lookup().category
+}
+
+// Retryable indicates if the same request may succeed when retried, as
+// PayPal failed to process it.
+func (r ResponseError) Retryable() bool {
+	return r.lookup().retryable
+}
+
+// Fault party responsible for the error, FaultUnknown if the code is not
+// in the catalogue.
+func (r ResponseError) Fault() Fault {
+	return r.lookup().fault
+}
+
+func (r ResponseError) lookup() errorCode {
+	if known, ok := errorCodes[r.Code]; ok {
+		return known
+	}
+
+	if code, err := strconv.Atoi(r.Code); err == nil && code >= massPayRangeStart && code <= massPayRangeEnd {
+		return errorCode{CategoryMassPay, false, FaultCaller}
+	}
+
+	return errorCode{CategoryUnknown, false, FaultUnknown}
+}
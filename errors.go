@@ -0,0 +1,129 @@
+package paypalnvp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrAuthentication the credentials were rejected or lack permission
+	// for the call.
+	ErrAuthentication = errors.New("Authentication failed")
+
+	// ErrInsufficientFunds the account balance does not cover the payment.
+	ErrInsufficientFunds = errors.New("Insufficient funds")
+
+	// ErrInvalidReceiver a receiver is invalid or cannot receive the
+	// payment.
+	ErrInvalidReceiver = errors.New("Invalid receiver")
+
+	// ErrDuplicateRequest the request duplicates one already processed.
+	ErrDuplicateRequest = errors.New("Duplicate request")
+
+	// ErrInternal PayPal failed to process the request.
+	ErrInternal = errors.New("Internal PayPal error")
+)
+
+type (
+	// APIError returned by the client when TypedErrors is set and PayPal
+	// reports a failure. It matches ErrAuthentication, ErrInsufficientFunds,
+	// ErrInvalidReceiver, ErrDuplicateRequest and ErrInternal with
+	// errors.Is, and each ResponseError with errors.As.
+	APIError struct {
+		Response *Response
+	}
+
+	// TransportError returned by the client when TypedErrors is set and the
+	// request could not be made or its response could not be read.
+	TransportError struct {
+		Err error
+	}
+
+	// SerializationError returned by the client when TypedErrors is set and
+	// the payload failed validation or serialization.
+	SerializationError struct {
+		Err error
+	}
+
+	// DecodeError returned by the client when TypedErrors is set and a
+	// field of the response could not be decoded. PayPal may still have
+	// processed the request, so the partly decoded response is returned
+	// with it and the request is not retried.
+	DecodeError struct {
+		Response *Response
+		Err      error
+	}
+)
+
+// Error Formatted error string listing the errors PayPal returned.
+func (e *APIError) Error() string {
+	message := fmt.Sprintf(
+		"NVP request failed with ACK '%s' and status code %d",
+		e.Response.Acknowledgement,
+		e.Response.statusCode(),
+	)
+
+	codes := make([]string, len(e.Response.Errors))
+	for i, responseError := range e.Response.Errors {
+		codes[i] = fmt.Sprintf("%s %s", responseError.Code, responseError.LongMessage)
+	}
+
+	if len(codes) == 0 {
+		return message
+	}
+
+	return message + ": " + strings.Join(codes, "; ")
+}
+
+// Errors errors PayPal returned, excluding warnings.
+func (e *APIError) Errors() []ResponseError {
+	return e.Response.Errors
+}
+
+// Is reports a server error status as ErrInternal, errors returned by
+// PayPal are matched through Unwrap.
+func (e *APIError) Is(target error) bool {
+	return target == ErrInternal && e.Response.statusCode() >= http.StatusInternalServerError
+}
+
+// Unwrap returns each ResponseError.
+func (e *APIError) Unwrap() []error {
+	errs := make([]error, len(e.Response.Errors))
+	for i, responseError := range e.Response.Errors {
+		errs[i] = responseError
+	}
+
+	return errs
+}
+
+// Error Formatted error string based on properties.
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("NVP request failed: %s", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Error Formatted error string based on properties.
+func (e *SerializationError) Error() string {
+	return fmt.Sprintf("Invalid NVP payload: %s", e.Err)
+}
+
+// Unwrap returns the underlying error, e.g. payload.ValidationErrors.
+func (e *SerializationError) Unwrap() error {
+	return e.Err
+}
+
+// Error Formatted error string based on properties.
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("Invalid NVP response: %s", e.Err)
+}
+
+// Unwrap returns the underlying error, e.g. nvp.DecodeError.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
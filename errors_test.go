@@ -0,0 +1,141 @@
+package paypalnvp_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+func NewTypedErrorsClient(httpClient MockClient) *paypalnvp.Client {
+	client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+	client.TypedErrors = true
+
+	return client
+}
+
+func TestTypedErrors(t *testing.T) {
+	failure := MockClient{
+		MockDo: func(request *http.Request) (*http.Response, error) {
+			return NewMockResponse([]byte(`ACK=Failure&L_ERRORCODE0=10002&L_LONGMESSAGE0=Security%20header%20is%20not%20valid&L_SEVERITYCODE0=Error`))
+		},
+	}
+
+	t.Run("ReturnsNoErrorForFailureWhenDisabled", func(t *testing.T) {
+		client, _ := paypalnvp.NewClient(failure, paypalnvp.Sandbox, "user", "password", "signature")
+		response, err := client.Execute(SerializedDataMock{})
+
+		if err != nil || response.Successful() {
+			t.Fatalf("Expected failed response and no error, got: %v", err)
+		}
+	})
+
+	t.Run("ReturnsAPIErrorForFailure", func(t *testing.T) {
+		response, err := NewTypedErrorsClient(failure).Execute(SerializedDataMock{})
+
+		apiError := &paypalnvp.APIError{}
+		if !errors.As(err, &apiError) || apiError.Response != response {
+			t.Fatalf("Expected *APIError with the response, got: %v", err)
+		}
+
+		if !errors.Is(err, paypalnvp.ErrAuthentication) || errors.Is(err, paypalnvp.ErrInsufficientFunds) {
+			t.Fatalf("Expected error to match only ErrAuthentication, got: %v", err)
+		}
+
+		responseError := paypalnvp.ResponseError{}
+		if !errors.As(err, &responseError) || responseError.Code != "10002" {
+			t.Fatalf("Expected ResponseError 10002, got: %+v", responseError)
+		}
+	})
+
+	t.Run("ReturnsNoErrorForWarning", func(t *testing.T) {
+		warning := MockClient{
+			MockDo: func(request *http.Request) (*http.Response, error) {
+				return NewMockResponse([]byte(`ACK=SuccessWithWarning&L_ERRORCODE0=11607&L_SEVERITYCODE0=Warning`))
+			},
+		}
+
+		if _, err := NewTypedErrorsClient(warning).Execute(SerializedDataMock{}); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	})
+
+	t.Run("MatchesServerErrorAsInternal", func(t *testing.T) {
+		serverError := MockClient{
+			MockDo: func(request *http.Request) (*http.Response, error) {
+				response, _ := NewMockResponse([]byte(``))
+				response.StatusCode = http.StatusBadGateway
+
+				return response, nil
+			},
+		}
+
+		_, err := NewTypedErrorsClient(serverError).Execute(SerializedDataMock{})
+		if !errors.Is(err, paypalnvp.ErrInternal) {
+			t.Fatalf("Expected ErrInternal, got: %v", err)
+		}
+	})
+
+	t.Run("WrapsTransportErrors", func(t *testing.T) {
+		clientError := errors.New("Client error")
+		transport := MockClient{
+			MockDo: func(request *http.Request) (*http.Response, error) {
+				return nil, clientError
+			},
+		}
+
+		_, err := NewTypedErrorsClient(transport).Execute(SerializedDataMock{})
+		transportError := &paypalnvp.TransportError{}
+		if !errors.As(err, &transportError) || !errors.Is(err, clientError) {
+			t.Fatalf("Expected *TransportError wrapping the client error, got: %v", err)
+		}
+	})
+
+	t.Run("WrapsDecodeErrors", func(t *testing.T) {
+		invalid := MockClient{
+			MockDo: func(request *http.Request) (*http.Response, error) {
+				return NewMockResponse([]byte(`ACK=Success&CORRELATIONID=5be53331d9700&TIMESTAMP=yesterday`))
+			},
+		}
+
+		response, err := NewTypedErrorsClient(invalid).Execute(SerializedDataMock{})
+		decodeError := &paypalnvp.DecodeError{}
+		if !errors.As(err, &decodeError) || !errors.As(err, &nvp.DecodeError{}) || errors.As(err, new(*paypalnvp.TransportError)) {
+			t.Fatalf("Expected *DecodeError wrapping nvp.DecodeError, got: %v", err)
+		}
+
+		if response == nil || decodeError.Response != response || response.CorrelationID != "5be53331d9700" {
+			t.Fatalf("Expected partly decoded response, got: %+v", response)
+		}
+	})
+
+	t.Run("WrapsSerializationErrors", func(t *testing.T) {
+		_, err := NewTypedErrorsClient(MockClient{}).Execute(payload.NewMassPayment("GBP", payload.ReceiverTypeEmail))
+
+		serializationError := &paypalnvp.SerializationError{}
+		if !errors.As(err, &serializationError) || !errors.As(err, &payload.ValidationErrors{}) {
+			t.Fatalf("Expected *SerializationError wrapping ValidationErrors, got: %v", err)
+		}
+	})
+
+	t.Run("DecodesFailedResponseInto", func(t *testing.T) {
+		response := paypalnvp.MassPayResponse{}
+		err := NewTypedErrorsClient(failure).ExecuteInto(SerializedDataMock{}, &response)
+
+		if err == nil || response.ErrorCount() != 1 {
+			t.Fatalf("Expected error and decoded response, got: %v, %d", err, response.ErrorCount())
+		}
+	})
+
+	t.Run("MatchesInsufficientFundsError", func(t *testing.T) {
+		err := error(paypalnvp.InsufficientFundsError{CurrencyCode: "GBP", Required: money.New(100, "GBP")})
+
+		if !errors.Is(err, paypalnvp.ErrInsufficientFunds) {
+			t.Fatalf("Expected ErrInsufficientFunds, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package paypalnvp
+
+import (
+	"time"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+type (
+	// SetExpressCheckoutResponse struct for response from a
+	// SetExpressCheckout request.
+	SetExpressCheckoutResponse struct {
+		Response
+		Token string `nvp_field:"TOKEN"`
+	}
+
+	// GetExpressCheckoutDetailsResponse struct for response from a
+	// GetExpressCheckoutDetails request.
+	GetExpressCheckoutDetailsResponse struct {
+		Response
+		Token           string `nvp_field:"TOKEN"`
+		CheckoutStatus  string `nvp_field:"CHECKOUTSTATUS"`
+		PayerID         string `nvp_field:"PAYERID"`
+		PayerStatus     string `nvp_field:"PAYERSTATUS"`
+		Email           string `nvp_field:"EMAIL"`
+		FirstName       string `nvp_field:"FIRSTNAME"`
+		LastName        string `nvp_field:"LASTNAME"`
+		CountryCode     string `nvp_field:"COUNTRYCODE"`
+		PaymentRequests []payload.PaymentRequest
+	}
+
+	// DoExpressCheckoutPaymentResponse struct for response from a
+	// DoExpressCheckoutPayment request.
+	DoExpressCheckoutPaymentResponse struct {
+		Response
+		Token       string `nvp_field:"TOKEN"`
+		PaymentInfo []PaymentInfo
+	}
+
+	// PaymentInfo contains the result of an individual payment request
+	// within a completed express checkout.
+	PaymentInfo struct {
+		TransactionID    string          `nvp_field:"PAYMENTINFO_%d_TRANSACTIONID"`
+		TransactionType  string          `nvp_field:"PAYMENTINFO_%d_TRANSACTIONTYPE"`
+		PaymentType      string          `nvp_field:"PAYMENTINFO_%d_PAYMENTTYPE"`
+		OrderTime        time.Time       `nvp_field:"PAYMENTINFO_%d_ORDERTIME"`
+		Amount           money.Amount    `nvp_field:"PAYMENTINFO_%d_AMT"`
+		FeeAmount        money.Amount    `nvp_field:"PAYMENTINFO_%d_FEEAMT"`
+		TaxAmount        money.Amount    `nvp_field:"PAYMENTINFO_%d_TAXAMT"`
+		CurrencyCode     string          `nvp_field:"PAYMENTINFO_%d_CURRENCYCODE"`
+		PaymentStatus    string          `nvp_field:"PAYMENTINFO_%d_PAYMENTSTATUS"`
+		PendingReason    string          `nvp_field:"PAYMENTINFO_%d_PENDINGREASON"`
+		ReasonCode       string          `nvp_field:"PAYMENTINFO_%d_REASONCODE"`
+		PaymentRequestID string          `nvp_field:"PAYMENTINFO_%d_PAYMENTREQUESTID"`
+		Acknowledgement  Acknowledgement `nvp_field:"PAYMENTINFO_%d_ACK"`
+		ErrorCode        string          `nvp_field:"PAYMENTINFO_%d_ERRORCODE"`
+	}
+)
+
+// ShippingAddress returns the shipping address of the first payment
+// request, or nil if the buyer did not provide one.
+func (r GetExpressCheckoutDetailsResponse) ShippingAddress() *payload.ShippingAddress {
+	if len(r.PaymentRequests) == 0 {
+		return nil
+	}
+
+	return r.PaymentRequests[0].ShipTo
+}
+
+// TransactionIDs returns the transaction ID of each completed payment
+// request.
+func (r DoExpressCheckoutPaymentResponse) TransactionIDs() []string {
+	transactionIDs := make([]string, 0, len(r.PaymentInfo))
+	for _, paymentInfo := range r.PaymentInfo {
+		if paymentInfo.TransactionID != "" {
+			transactionIDs = append(transactionIDs, paymentInfo.TransactionID)
+		}
+	}
+
+	return transactionIDs
+}
+
+func (r *GetExpressCheckoutDetailsResponse) setCurrencies() {
+	for i, paymentRequest := range r.PaymentRequests {
+		r.PaymentRequests[i] = paymentRequest.InCurrency()
+	}
+}
+
+func (r *DoExpressCheckoutPaymentResponse) setCurrencies() {
+	for i := range r.PaymentInfo {
+		paymentInfo := &r.PaymentInfo[i]
+		paymentInfo.Amount = paymentInfo.Amount.WithCurrency(paymentInfo.CurrencyCode)
+		paymentInfo.FeeAmount = paymentInfo.FeeAmount.WithCurrency(paymentInfo.CurrencyCode)
+		paymentInfo.TaxAmount = paymentInfo.TaxAmount.WithCurrency(paymentInfo.CurrencyCode)
+	}
+}
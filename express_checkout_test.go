@@ -0,0 +1,94 @@
+package paypalnvp_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+)
+
+func TestExpressCheckout(t *testing.T) {
+	t.Run("GetExpressCheckoutDetailsResponse", func(t *testing.T) {
+		t.Run("DecodesPayerAndShippingAddress", func(t *testing.T) {
+			data := `ACK=Success&TOKEN=EC-123&PAYERID=PAYER1&EMAIL=buyer%40test.com&PAYMENTREQUEST_0_AMT=1500&PAYMENTREQUEST_0_CURRENCYCODE=JPY&PAYMENTREQUEST_0_SHIPTONAME=Test+Buyer&PAYMENTREQUEST_0_SHIPTOCITY=London&L_PAYMENTREQUEST_0_NAME0=Video`
+			httpResponse := &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+				StatusCode: 200,
+			}
+
+			response, _ := paypalnvp.NewResponse(httpResponse)
+			details := paypalnvp.GetExpressCheckoutDetailsResponse{}
+			if err := response.Decode(&details); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if details.Acknowledgement != "Success" {
+				t.Fatalf("Expected Acknowledgement to be 'Success', got: '%s'", details.Acknowledgement)
+			}
+
+			if details.PayerID != "PAYER1" {
+				t.Fatalf("Expected PayerID to be 'PAYER1', got: '%s'", details.PayerID)
+			}
+
+			address := details.ShippingAddress()
+			if address == nil || address.Name != "Test Buyer" || address.City != "London" {
+				t.Fatalf("Expected shipping address to be decoded, got: %v", address)
+			}
+
+			if len(details.PaymentRequests[0].Items) != 1 {
+				t.Fatalf("Expected 1 item, got: %d", len(details.PaymentRequests[0].Items))
+			}
+
+			amount := details.PaymentRequests[0].Amount
+			if amount.String() != "1500" || amount.Currency() != "JPY" {
+				t.Fatalf("Expected amount of 1500 JPY, got: %s %s", amount, amount.Currency())
+			}
+		})
+	})
+
+	t.Run("DoExpressCheckoutPaymentResponse", func(t *testing.T) {
+		t.Run("DecodesTransactionIDs", func(t *testing.T) {
+			data := `ACK=Success&TOKEN=EC-123&PAYMENTINFO_0_TRANSACTIONID=TX1&PAYMENTINFO_0_AMT=15.00&PAYMENTINFO_0_CURRENCYCODE=GBP&PAYMENTINFO_1_TRANSACTIONID=TX2&PAYMENTINFO_1_AMT=500&PAYMENTINFO_1_CURRENCYCODE=JPY`
+			httpResponse := &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+				StatusCode: 200,
+			}
+
+			response, _ := paypalnvp.NewResponse(httpResponse)
+			payment := paypalnvp.DoExpressCheckoutPaymentResponse{}
+			response.Decode(&payment)
+
+			transactionIDs := payment.TransactionIDs()
+			if len(transactionIDs) != 2 || transactionIDs[1] != "TX2" {
+				t.Fatalf("Expected transaction IDs [TX1 TX2], got: %v", transactionIDs)
+			}
+
+			if payment.PaymentInfo[0].Amount != money.MustParse("15.00", "GBP") || payment.PaymentInfo[1].Amount != money.MustParse("500", "JPY") {
+				t.Fatalf("Expected amounts of 15.00 GBP and 500 JPY, got: %v", payment.PaymentInfo)
+			}
+		})
+	})
+
+	t.Run(".ExpressCheckoutURL", func(t *testing.T) {
+		t.Run("UsesSandboxURL", func(t *testing.T) {
+			client, _ := paypalnvp.NewClient(nil, paypalnvp.Sandbox, "user", "password", "signature")
+			expectedURL := "https://www.sandbox.paypal.com/cgi-bin/webscr?cmd=_express-checkout&token=EC-123"
+
+			if client.ExpressCheckoutURL("EC-123") != expectedURL {
+				t.Fatalf("Expected URL to be '%s', got: '%s'", expectedURL, client.ExpressCheckoutURL("EC-123"))
+			}
+		})
+
+		t.Run("UsesLiveURL", func(t *testing.T) {
+			client, _ := paypalnvp.NewClient(nil, paypalnvp.Live, "user", "password", "signature")
+			expectedURL := "https://www.paypal.com/cgi-bin/webscr?cmd=_express-checkout&token=EC-123"
+
+			if client.ExpressCheckoutURL("EC-123") != expectedURL {
+				t.Fatalf("Expected URL to be '%s', got: '%s'", expectedURL, client.ExpressCheckoutURL("EC-123"))
+			}
+		})
+	})
+}
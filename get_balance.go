@@ -0,0 +1,109 @@
+package paypalnvp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+type (
+	// GetBalanceResponse struct for response from a GetBalance request.
+	GetBalanceResponse struct {
+		Response
+		Balances []Balance
+	}
+
+	// Balance contains the balance held in a single currency.
+	Balance struct {
+		Amount       money.Amount `nvp_field:"L_AMT%d"`
+		CurrencyCode string       `nvp_field:"L_CURRENCYCODE%d"`
+	}
+
+	// InsufficientFundsError returned when the account balance does not
+	// cover a payment.
+	InsufficientFundsError struct {
+		CurrencyCode string
+		Available    money.Amount
+		Required     money.Amount
+	}
+)
+
+// ByCurrency returns the balances keyed by currency code, each amount in
+// its currency.
+func (r GetBalanceResponse) ByCurrency() map[string]money.Amount {
+	balances := make(map[string]money.Amount, len(r.Balances))
+	for _, balance := range r.Balances {
+		total := balances[balance.CurrencyCode].WithCurrency(balance.CurrencyCode)
+		balances[balance.CurrencyCode] = total.Add(balance.Amount)
+	}
+
+	return balances
+}
+
+func (r *GetBalanceResponse) setCurrencies() {
+	for i := range r.Balances {
+		r.Balances[i].Amount = r.Balances[i].Amount.WithCurrency(r.Balances[i].CurrencyCode)
+	}
+}
+
+// Shortfall amount missing from the balance to cover the payment.
+func (e InsufficientFundsError) Shortfall() money.Amount {
+	return e.Required.Sub(e.Available)
+}
+
+// Is matches ErrInsufficientFunds.
+func (e InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// Error Formatted error string based on properties.
+func (e InsufficientFundsError) Error() string {
+	return fmt.Sprintf(
+		"Insufficient funds: %s %s required, %s %s available, short by %s %s",
+		e.Required,
+		e.CurrencyCode,
+		e.Available,
+		e.CurrencyCode,
+		e.Shortfall(),
+		e.CurrencyCode,
+	)
+}
+
+// CheckFunds fetches the account balance and returns an
+// InsufficientFundsError if it does not cover the total of the mass
+// payment. PayPal fees are not included in the total.
+func (c Client) CheckFunds(massPayment *payload.MassPayment) error {
+	return c.CheckFundsContext(context.Background(), massPayment)
+}
+
+// CheckFundsContext fetches the account balance with ctx and returns an
+// InsufficientFundsError if it does not cover the total of the mass
+// payment.
+func (c Client) CheckFundsContext(ctx context.Context, massPayment *payload.MassPayment) error {
+	response := GetBalanceResponse{}
+	if err := c.ExecuteIntoContext(ctx, payload.NewGetBalance(true), &response); err != nil {
+		return err
+	}
+
+	if !response.Successful() {
+		if len(response.Errors) > 0 {
+			return response.Errors[0]
+		}
+
+		return fmt.Errorf("GetBalance failed with ACK '%s' and status code %d", response.Acknowledgement, response.statusCode())
+	}
+
+	available := response.ByCurrency()[massPayment.CurrencyCode].WithCurrency(massPayment.CurrencyCode)
+	required := massPayment.Total()
+	if available.Cmp(required) < 0 {
+		return InsufficientFundsError{
+			CurrencyCode: massPayment.CurrencyCode,
+			Available:    available,
+			Required:     required,
+		}
+	}
+
+	return nil
+}
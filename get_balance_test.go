@@ -0,0 +1,79 @@
+package paypalnvp_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+func NewBalanceClient(body string) *paypalnvp.Client {
+	httpClient := MockClient{
+		MockDo: func(request *http.Request) (*http.Response, error) {
+			return NewMockResponse([]byte(body))
+		},
+	}
+
+	client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+
+	return client
+}
+
+func TestGetBalance(t *testing.T) {
+	balances := `ACK=Success&L_AMT0=100.50&L_CURRENCYCODE0=GBP&L_AMT1=20.00&L_CURRENCYCODE1=USD`
+
+	t.Run("GetBalanceResponse", func(t *testing.T) {
+		t.Run("DecodesBalancesByCurrency", func(t *testing.T) {
+			response := paypalnvp.GetBalanceResponse{}
+			NewBalanceClient(balances).ExecuteInto(payload.NewGetBalance(true), &response)
+
+			byCurrency := response.ByCurrency()
+			if len(byCurrency) != 2 || byCurrency["GBP"] != money.MustParse("100.50", "GBP") || byCurrency["USD"] != money.MustParse("20.00", "USD") {
+				t.Fatalf("Expected GBP 100.50 and USD 20.00, got: %v", byCurrency)
+			}
+
+			if response.Balances[0].Amount.Currency() != "GBP" || response.Balances[1].Amount.Currency() != "USD" {
+				t.Fatalf("Expected balances in their currency, got: %v", response.Balances)
+			}
+		})
+	})
+
+	t.Run(".CheckFunds", func(t *testing.T) {
+		t.Run("ReturnsNilWhenBalanceCoversTotal", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Amount: money.MustParse("100.50", "GBP")})
+
+			if err := NewBalanceClient(balances).CheckFunds(massPayment); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsShortfallWhenBalanceTooLow", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("USD", payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Amount: money.MustParse("15.00", "USD")})
+			massPayment.AddItem(payload.MassPaymentItem{Amount: money.MustParse("10.00", "USD")})
+
+			err := NewBalanceClient(balances).CheckFunds(massPayment)
+			insufficientFunds, ok := err.(paypalnvp.InsufficientFundsError)
+			if !ok {
+				t.Fatalf("Expected InsufficientFundsError, got: %v", err)
+			}
+
+			if insufficientFunds.Shortfall() != money.MustParse("5.00", "USD") {
+				t.Fatalf("Expected Shortfall() to be 5.00, got: %s", insufficientFunds.Shortfall())
+			}
+		})
+
+		t.Run("ReturnsErrorWhenRequestFails", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Amount: money.MustParse("1.00", "GBP")})
+
+			err := NewBalanceClient(`ACK=Failure&L_ERRORCODE0=10002&L_SEVERITYCODE0=Error`).CheckFunds(massPayment)
+			if _, ok := err.(paypalnvp.ResponseError); !ok {
+				t.Fatalf("Expected ResponseError, got: %v", err)
+			}
+		})
+	})
+}
@@ -0,0 +1,5 @@
+module github.com/vidsy/go-paypalnvp
+
+go 1.21
+
+require golang.org/x/crypto v0.31.0
@@ -0,0 +1,62 @@
+package paypalnvp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+type (
+	// Call a validated and serialized payload on its way to PayPal.
+	// Interceptors may change Values and Header before the request is
+	// made.
+	Call struct {
+		// Payload payload passed to Execute.
+		Payload payload.Serializer
+
+		// Values serialized payload, including credentials.
+		Values url.Values
+
+		// Header extra HTTP headers sent with each attempt.
+		Header http.Header
+	}
+
+	// Invoker performs a call, returning the response.
+	Invoker func(ctx context.Context, call *Call) (*Response, error)
+
+	// Interceptor wraps a call made by Execute. It calls next to continue
+	// the chain, and may modify the call beforehand, or the response and
+	// error afterwards. Returning without calling next short-circuits the
+	// call, so no request is made, and the returned response need not hold
+	// an http.Response. The last Invoker in the chain performs the request,
+	// including any retries.
+	Interceptor func(ctx context.Context, call *Call, next Invoker) (*Response, error)
+)
+
+// Method NVP method of the call.
+func (c Call) Method() string {
+	return c.Values.Get(methodField)
+}
+
+func (c Client) intercept(ctx context.Context, call *Call) (*Response, error) {
+	invoker := c.invoke
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.Interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) (*Response, error) {
+			return interceptor(ctx, call, next)
+		}
+	}
+
+	return invoker(ctx, call)
+}
+
+func (c Client) invoke(ctx context.Context, call *Call) (*Response, error) {
+	response, err := c.executeWithRetry(ctx, call)
+	if err != nil {
+		return response, c.requestError(response, err)
+	}
+
+	return response, nil
+}
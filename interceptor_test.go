@@ -0,0 +1,153 @@
+package paypalnvp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+func TestInterceptors(t *testing.T) {
+	t.Run(".Execute", func(t *testing.T) {
+		t.Run("RunsInterceptorsInOrder", func(t *testing.T) {
+			order := []string{}
+			record := func(name string) paypalnvp.Interceptor {
+				return func(ctx context.Context, call *paypalnvp.Call, next paypalnvp.Invoker) (*paypalnvp.Response, error) {
+					order = append(order, name+" before")
+					response, err := next(ctx, call)
+					order = append(order, name+" after")
+					return response, err
+				}
+			}
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					order = append(order, "request")
+					return NewMockResponse([]byte(`ACK=Success`))
+				},
+			}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.Interceptors = []paypalnvp.Interceptor{record("first"), record("second")}
+
+			client.Execute(SerializedDataMock{})
+
+			expected := "first before, second before, request, second after, first after"
+			if strings.Join(order, ", ") != expected {
+				t.Fatalf("Expected '%s', got: '%s'", expected, strings.Join(order, ", "))
+			}
+		})
+
+		t.Run("SendsModifiedValuesAndHeaders", func(t *testing.T) {
+			var request *http.Request
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					request = req
+					req.ParseForm()
+					return NewMockResponse([]byte(`ACK=Success`))
+				},
+			}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.Interceptors = []paypalnvp.Interceptor{
+				func(ctx context.Context, call *paypalnvp.Call, next paypalnvp.Invoker) (*paypalnvp.Response, error) {
+					if call.Values.Get("SOME") != "Data" || call.Values.Get("USER") != "user" {
+						t.Fatalf("Expected serialized values with credentials, got: %v", call.Values)
+					}
+
+					call.Values.Set("MSGSUBID", "abc")
+					call.Header.Set("X-Request-ID", "123")
+					return next(ctx, call)
+				},
+			}
+
+			client.Execute(SerializedDataMock{})
+
+			if request.PostForm.Get("MSGSUBID") != "abc" {
+				t.Fatalf("Expected MSGSUBID to be sent, got: %v", request.PostForm)
+			}
+
+			if request.Header.Get("X-Request-ID") != "123" || request.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+				t.Fatalf("Expected headers to be sent, got: %v", request.Header)
+			}
+		})
+
+		t.Run("ShortCircuits", func(t *testing.T) {
+			calls := 0
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					calls++
+					return NewMockResponse(nil)
+				},
+			}
+			cached := &paypalnvp.Response{Response: &http.Response{StatusCode: 200}, Acknowledgement: paypalnvp.AckSuccess}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.Interceptors = []paypalnvp.Interceptor{
+				func(ctx context.Context, call *paypalnvp.Call, next paypalnvp.Invoker) (*paypalnvp.Response, error) {
+					return cached, nil
+				},
+			}
+
+			response, err := client.Execute(SerializedDataMock{})
+
+			if response != cached || err != nil || calls != 0 {
+				t.Fatalf("Expected cached response without request, got: %v, %v, %d calls", response, err, calls)
+			}
+		})
+
+		t.Run("ShortCircuitsWithoutHTTPResponse", func(t *testing.T) {
+			for _, ack := range []paypalnvp.Acknowledgement{paypalnvp.AckSuccess, paypalnvp.AckFailure} {
+				cached := &paypalnvp.Response{Acknowledgement: ack}
+				tracer := &MockTracer{}
+				client, _ := paypalnvp.NewClient(MockClient{}, paypalnvp.Sandbox, "user", "password", "signature")
+				client.TypedErrors = true
+				client.Tracer = tracer
+				client.Interceptors = []paypalnvp.Interceptor{
+					func(ctx context.Context, call *paypalnvp.Call, next paypalnvp.Invoker) (*paypalnvp.Response, error) {
+						return cached, nil
+					},
+				}
+
+				response, err := client.Execute(SerializedDataMock{})
+
+				var apiError *paypalnvp.APIError
+				if response != cached || errors.As(err, &apiError) != ack.IsFailure() {
+					t.Fatalf("Expected cached response with an *APIError only for failures, got: %v, %v", response, err)
+				}
+
+				span := tracer.Spans[0]
+				if _, ok := span.Attributes[paypalnvp.AttributeHTTPStatusCode]; ok || !span.Ended {
+					t.Fatalf("Expected ended span without status code, got: %+v", span)
+				}
+			}
+		})
+
+		t.Run("ReturnsModifiedResponse", func(t *testing.T) {
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					return NewMockResponse([]byte(`ACK=Failure&L_ERRORCODE0=10321&L_SEVERITYCODE0=Error`))
+				},
+			}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.TypedErrors = true
+			client.Interceptors = []paypalnvp.Interceptor{
+				func(ctx context.Context, call *paypalnvp.Call, next paypalnvp.Invoker) (*paypalnvp.Response, error) {
+					if call.Method() != "" {
+						t.Fatalf("Expected no method, got: '%s'", call.Method())
+					}
+
+					response, err := next(ctx, call)
+					response.Acknowledgement = paypalnvp.AckSuccess
+					response.Errors = nil
+					return response, err
+				},
+			}
+
+			_, err := client.Execute(SerializedDataMock{})
+
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+	})
+}
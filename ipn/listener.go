@@ -0,0 +1,195 @@
+package ipn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+const (
+	sandboxPostbackEndpoint = "https://ipnpb.sandbox.paypal.com/cgi-bin/webscr"
+	livePostbackEndpoint    = "https://ipnpb.paypal.com/cgi-bin/webscr"
+
+	validateCommand = "cmd=_notify-validate&"
+	verified        = "VERIFIED"
+	invalid         = "INVALID"
+	maxBodySize     = 1048576
+)
+
+// ErrInvalid returned by Verify when PayPal did not send the notification.
+var ErrInvalid = errors.New("IPN notification is INVALID")
+
+type (
+	// Listener http.Handler receiving notifications. Each notification is
+	// posted back to PayPal for verification and, once verified, passed to
+	// OnMassPay or OnNotification. PayPal redelivers notifications that are
+	// not acknowledged with a 200 status, so callbacks returning an error
+	// will see the notification again.
+	Listener struct {
+		client      paypalnvp.TransportClient
+		environment paypalnvp.Environment
+
+		// Endpoint overrides the postback URL derived from the
+		// environment.
+		Endpoint string
+
+		// OnMassPay called with verified mass payment notifications.
+		OnMassPay func(ctx context.Context, notification *MassPayNotification) error
+
+		// OnNotification called with every other verified notification.
+		OnNotification func(ctx context.Context, notification *Notification) error
+
+		// OnParseError called with verified notifications that cannot be
+		// parsed. They are acknowledged, as PayPal would otherwise
+		// redeliver them, so should be recorded for investigation.
+		OnParseError func(ctx context.Context, data url.Values, err error)
+	}
+)
+
+// NewListener Creates a new listener verifying notifications with the
+// environment through client, returning an error if the environment is
+// unknown.
+func NewListener(client paypalnvp.TransportClient, environment paypalnvp.Environment) (*Listener, error) {
+	if err := environment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &Listener{
+		client:      client,
+		environment: environment,
+	}, nil
+}
+
+// ServeHTTP verifies and dispatches a notification. It responds with 400
+// for notifications PayPal reports as INVALID, and 500 if verification or
+// a callback fails so PayPal retries. Verified notifications that cannot be
+// parsed are passed to OnParseError and acknowledged with 200.
+func (l *Listener) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "Unable to read notification", http.StatusBadRequest)
+		return
+	}
+
+	data, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Unable to parse notification", http.StatusBadRequest)
+		return
+	}
+
+	if err := l.Verify(r.Context(), body); err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrInvalid {
+			status = http.StatusBadRequest
+		}
+
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := l.dispatch(r.Context(), data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Verify posts the raw notification body back to PayPal, returning nil if
+// PayPal reports it as VERIFIED and ErrInvalid if INVALID.
+func (l *Listener) Verify(ctx context.Context, body []byte) error {
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		l.postbackEndpoint(),
+		io.MultiReader(strings.NewReader(validateCommand), bytes.NewReader(body)),
+	)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := l.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	result, err := ioutil.ReadAll(io.LimitReader(response.Body, maxBodySize))
+	if err != nil {
+		return err
+	}
+
+	switch strings.TrimSpace(string(result)) {
+	case verified:
+		return nil
+	case invalid:
+		return ErrInvalid
+	}
+
+	return fmt.Errorf("Unexpected IPN verification response with status code %d", response.StatusCode)
+}
+
+func (l *Listener) dispatch(ctx context.Context, data url.Values) error {
+	if data.Get("txn_type") == TransactionTypeMassPay {
+		notification, err := ParseMassPay(data)
+		if err != nil {
+			l.parseError(ctx, data, err)
+			return nil
+		}
+
+		if l.OnMassPay == nil {
+			return nil
+		}
+
+		return l.OnMassPay(ctx, notification)
+	}
+
+	notification, err := Parse(data)
+	if err != nil {
+		l.parseError(ctx, data, err)
+		return nil
+	}
+
+	if l.OnNotification == nil {
+		return nil
+	}
+
+	return l.OnNotification(ctx, notification)
+}
+
+func (l *Listener) parseError(ctx context.Context, data url.Values, err error) {
+	if l.OnParseError != nil {
+		l.OnParseError(ctx, data, err)
+	}
+}
+
+func (l *Listener) postbackEndpoint() string {
+	if l.Endpoint != "" {
+		return l.Endpoint
+	}
+
+	if l.environment == paypalnvp.Live {
+		return livePostbackEndpoint
+	}
+
+	return sandboxPostbackEndpoint
+}
@@ -0,0 +1,237 @@
+package ipn_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/ipn"
+)
+
+const massPayNotification = "txn_type=masspay&payment_status=Processed&payer_email=payer%40example.com&test_ipn=1&ipn_track_id=abc123" +
+	"&masspay_txn_id_1=TX1&status_1=Completed&unique_id_1=u1&receiver_email_1=one%40example.com&mc_gross_1=10.50&mc_fee_1=0.21&mc_currency_1=GBP" +
+	"&masspay_txn_id_2=TX2&status_2=Unclaimed&unique_id_2=u2&receiver_email_2=two%40example.com&mc_gross_2=1000&mc_currency_2=JPY"
+
+type MockClient struct {
+	MockDo func(*http.Request) (*http.Response, error)
+}
+
+func (mc MockClient) Do(req *http.Request) (*http.Response, error) {
+	return mc.MockDo(req)
+}
+
+func NewVerifyingClient(result string, body *string) MockClient {
+	return MockClient{
+		MockDo: func(req *http.Request) (*http.Response, error) {
+			data, _ := ioutil.ReadAll(req.Body)
+			if body != nil {
+				*body = string(data)
+			}
+
+			return &http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader(result)),
+				StatusCode: 200,
+			}, nil
+		},
+	}
+}
+
+func Notify(listener *ipn.Listener, method string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, "/ipn", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	listener.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestListener(t *testing.T) {
+	t.Run("NewListener", func(t *testing.T) {
+		t.Run("ReturnsErrorForUnknownEnvironment", func(t *testing.T) {
+			_, err := ipn.NewListener(nil, "prod")
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+
+	t.Run(".ServeHTTP", func(t *testing.T) {
+		t.Run("PostsNotificationBackForVerification", func(t *testing.T) {
+			var postback, endpoint string
+			client := NewVerifyingClient("VERIFIED", &postback)
+			do := client.MockDo
+			client.MockDo = func(req *http.Request) (*http.Response, error) {
+				endpoint = req.URL.String()
+				return do(req)
+			}
+
+			listener, _ := ipn.NewListener(client, paypalnvp.Live)
+			recorder := Notify(listener, http.MethodPost, massPayNotification)
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected status code 200, got: %d", recorder.Code)
+			}
+
+			if postback != "cmd=_notify-validate&"+massPayNotification {
+				t.Fatalf("Expected notification to be posted back unchanged, got: '%s'", postback)
+			}
+
+			if endpoint != "https://ipnpb.paypal.com/cgi-bin/webscr" {
+				t.Fatalf("Expected live postback endpoint, got: '%s'", endpoint)
+			}
+		})
+
+		t.Run("DispatchesMassPayNotification", func(t *testing.T) {
+			var received *ipn.MassPayNotification
+			listener, _ := ipn.NewListener(NewVerifyingClient("VERIFIED", nil), paypalnvp.Sandbox)
+			listener.OnMassPay = func(ctx context.Context, notification *ipn.MassPayNotification) error {
+				received = notification
+				return nil
+			}
+
+			Notify(listener, http.MethodPost, massPayNotification)
+
+			if received == nil {
+				t.Fatalf("Expected OnMassPay to be called")
+			}
+
+			if !received.Test || received.PayerEmail != "payer@example.com" || received.TrackID != "abc123" {
+				t.Fatalf("Expected notification fields to be parsed, got: %+v", received.Notification)
+			}
+
+			if len(received.Items) != 2 {
+				t.Fatalf("Expected 2 items, got: %d", len(received.Items))
+			}
+
+			first, second := received.Items[0], received.Items[1]
+			if first.TransactionID != "TX1" || first.Status != ipn.MassPayStatusCompleted || first.UniqueID != "u1" {
+				t.Fatalf("Expected first item to be parsed, got: %+v", first)
+			}
+
+			if first.Gross.String() != "10.50" || first.Gross.Currency() != "GBP" || first.Fee.String() != "0.21" {
+				t.Fatalf("Expected first item amounts of 10.50 and 0.21 GBP, got: %s and %s %s", first.Gross, first.Fee, first.Gross.Currency())
+			}
+
+			if second.Status != ipn.MassPayStatusUnclaimed || second.Gross.String() != "1000" || !second.Fee.IsZero() {
+				t.Fatalf("Expected second item to be parsed, got: %+v", second)
+			}
+		})
+
+		t.Run("DispatchesOtherNotifications", func(t *testing.T) {
+			var received *ipn.Notification
+			listener, _ := ipn.NewListener(NewVerifyingClient("VERIFIED", nil), paypalnvp.Sandbox)
+			listener.OnNotification = func(ctx context.Context, notification *ipn.Notification) error {
+				received = notification
+				return nil
+			}
+
+			Notify(listener, http.MethodPost, "txn_type=web_accept&payment_status=Completed&custom=order-1")
+
+			if received == nil || received.PaymentStatus != "Completed" || received.Values.Get("custom") != "order-1" {
+				t.Fatalf("Expected OnNotification to be called with parsed notification, got: %+v", received)
+			}
+		})
+
+		t.Run("RejectsInvalidNotification", func(t *testing.T) {
+			called := false
+			listener, _ := ipn.NewListener(NewVerifyingClient("INVALID", nil), paypalnvp.Sandbox)
+			listener.OnMassPay = func(ctx context.Context, notification *ipn.MassPayNotification) error {
+				called = true
+				return nil
+			}
+
+			recorder := Notify(listener, http.MethodPost, massPayNotification)
+
+			if recorder.Code != http.StatusBadRequest || called {
+				t.Fatalf("Expected status code 400 without dispatch, got: %d, %t", recorder.Code, called)
+			}
+		})
+
+		t.Run("FailsWhenVerificationFails", func(t *testing.T) {
+			client := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					return nil, errors.New("connection refused")
+				},
+			}
+			listener, _ := ipn.NewListener(client, paypalnvp.Sandbox)
+
+			recorder := Notify(listener, http.MethodPost, massPayNotification)
+
+			if recorder.Code != http.StatusInternalServerError {
+				t.Fatalf("Expected status code 500, got: %d", recorder.Code)
+			}
+		})
+
+		t.Run("FailsWhenCallbackFails", func(t *testing.T) {
+			listener, _ := ipn.NewListener(NewVerifyingClient("VERIFIED", nil), paypalnvp.Sandbox)
+			listener.OnMassPay = func(ctx context.Context, notification *ipn.MassPayNotification) error {
+				return errors.New("database unavailable")
+			}
+
+			recorder := Notify(listener, http.MethodPost, massPayNotification)
+
+			if recorder.Code != http.StatusInternalServerError {
+				t.Fatalf("Expected status code 500, got: %d", recorder.Code)
+			}
+		})
+
+		t.Run("AcknowledgesUnparseableNotification", func(t *testing.T) {
+			var parseErr error
+			called := false
+			listener, _ := ipn.NewListener(NewVerifyingClient("VERIFIED", nil), paypalnvp.Sandbox)
+			listener.OnMassPay = func(ctx context.Context, notification *ipn.MassPayNotification) error {
+				called = true
+				return nil
+			}
+			listener.OnParseError = func(ctx context.Context, data url.Values, err error) {
+				parseErr = err
+			}
+
+			recorder := Notify(listener, http.MethodPost, "txn_type=masspay&masspay_txn_id_1=TX1&mc_gross_1=ten")
+
+			if recorder.Code != http.StatusOK || called {
+				t.Fatalf("Expected status code 200 without dispatch, got: %d, %t", recorder.Code, called)
+			}
+
+			if parseErr == nil {
+				t.Fatalf("Expected OnParseError to be called")
+			}
+		})
+
+		t.Run("RejectsOtherMethods", func(t *testing.T) {
+			listener, _ := ipn.NewListener(NewVerifyingClient("VERIFIED", nil), paypalnvp.Sandbox)
+
+			recorder := Notify(listener, http.MethodGet, "")
+
+			if recorder.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("Expected status code 405, got: %d", recorder.Code)
+			}
+		})
+	})
+
+	t.Run("ParseMassPay", func(t *testing.T) {
+		t.Run("IgnoresEmptyAmounts", func(t *testing.T) {
+			data := url.Values{"masspay_txn_id_1": {"TX1"}, "mc_gross_1": {"10.50"}, "mc_fee_1": {""}, "mc_currency_1": {"GBP"}}
+
+			notification, err := ipn.ParseMassPay(data)
+			if err != nil || len(notification.Items) != 1 || notification.Items[0].Gross.String() != "10.50" || !notification.Items[0].Fee.IsZero() {
+				t.Fatalf("Expected item without a fee, got: %v, %+v", err, notification)
+			}
+		})
+
+		t.Run("ReturnsErrorForInvalidAmount", func(t *testing.T) {
+			data := url.Values{"masspay_txn_id_1": {"TX1"}, "mc_gross_1": {"ten"}}
+
+			_, err := ipn.ParseMassPay(data)
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+		})
+	})
+}
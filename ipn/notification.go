@@ -0,0 +1,136 @@
+// Package ipn receives PayPal Instant Payment Notifications, verifying each
+// with PayPal before passing it on, e.g. to learn the outcome of each item
+// of a mass payment.
+package ipn
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+const (
+	// TransactionTypeMassPay txn_type of mass payment notifications.
+	TransactionTypeMassPay = "masspay"
+
+	// MassPayStatusCompleted the item was paid.
+	MassPayStatusCompleted = "Completed"
+
+	// MassPayStatusFailed the item could not be paid.
+	MassPayStatusFailed = "Failed"
+
+	// MassPayStatusReversed the item was paid and later reversed.
+	MassPayStatusReversed = "Reversed"
+
+	// MassPayStatusUnclaimed the receiver has no PayPal account and has
+	// not yet claimed the item.
+	MassPayStatusUnclaimed = "Unclaimed"
+)
+
+type (
+	// Notification fields common to every notification.
+	Notification struct {
+		TransactionType string `nvp_field:"txn_type"`
+		PaymentStatus   string `nvp_field:"payment_status"`
+		PayerID         string `nvp_field:"payer_id"`
+		PayerEmail      string `nvp_field:"payer_email"`
+		ReceiverEmail   string `nvp_field:"receiver_email"`
+		TrackID         string `nvp_field:"ipn_track_id"`
+		Charset         string `nvp_field:"charset"`
+		Test            bool   `nvp_field:"test_ipn"`
+
+		// PaymentDate date of the payment as sent by PayPal, e.g.
+		// "20:12:59 Jan 13, 2009 PST".
+		PaymentDate string `nvp_field:"payment_date"`
+
+		// Values every field of the notification.
+		Values url.Values `nvp_field:"-"`
+	}
+
+	// MassPayNotification notification of the outcome of the items of a
+	// mass payment. A mass payment may be reported over several
+	// notifications.
+	MassPayNotification struct {
+		Notification
+		Items []MassPayItem
+	}
+
+	// MassPayItem outcome of a single mass payment item.
+	MassPayItem struct {
+		TransactionID string       `nvp_field:"masspay_txn_id"`
+		Status        string       `nvp_field:"status"`
+		UniqueID      string       `nvp_field:"unique_id"`
+		ReceiverEmail string       `nvp_field:"receiver_email"`
+		Gross         money.Amount `nvp_field:"mc_gross"`
+		Fee           money.Amount `nvp_field:"mc_fee"`
+		CurrencyCode  string       `nvp_field:"mc_currency"`
+		ReasonCode    string       `nvp_field:"reason_code"`
+	}
+)
+
+// Parse parses the common fields of a notification.
+func Parse(data url.Values) (*Notification, error) {
+	notification := &Notification{}
+	if err := nvp.Unmarshal(data, notification); err != nil {
+		return nil, err
+	}
+	notification.Values = data
+
+	return notification, nil
+}
+
+// ParseMassPay parses a mass payment notification. Its items are numbered
+// from 1, e.g. masspay_txn_id_1, and are returned in order.
+func ParseMassPay(data url.Values) (*MassPayNotification, error) {
+	notification, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	massPay := &MassPayNotification{Notification: *notification}
+	for index := 1; data.Get(fmt.Sprintf("masspay_txn_id_%d", index)) != ""; index++ {
+		item, err := parseMassPayItem(data, index)
+		if err != nil {
+			return nil, err
+		}
+
+		massPay.Items = append(massPay.Items, item)
+	}
+
+	return massPay, nil
+}
+
+// MassPay indicates if the notification is for a mass payment.
+func (n Notification) MassPay() bool {
+	return n.TransactionType == TransactionTypeMassPay
+}
+
+func parseMassPayItem(data url.Values, index int) (MassPayItem, error) {
+	suffix := fmt.Sprintf("_%d", index)
+	fields := url.Values{}
+	for _, field := range []string{
+		"masspay_txn_id",
+		"status",
+		"unique_id",
+		"receiver_email",
+		"mc_gross",
+		"mc_fee",
+		"mc_currency",
+		"reason_code",
+	} {
+		if value, ok := data[field+suffix]; ok {
+			fields[field] = value
+		}
+	}
+
+	item := MassPayItem{}
+	if err := nvp.Unmarshal(fields, &item); err != nil {
+		return MassPayItem{}, err
+	}
+	item.Gross = item.Gross.WithCurrency(item.CurrencyCode)
+	item.Fee = item.Fee.WithCurrency(item.CurrencyCode)
+
+	return item, nil
+}
@@ -0,0 +1,154 @@
+package paypalnvp
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Redacted value logged in place of credentials and, when RedactPII is
+	// set, receiver details.
+	Redacted = "[REDACTED]"
+
+	methodField = "METHOD"
+
+	paymentRequestPrefix = "PAYMENTREQUEST_"
+)
+
+type (
+	// Logger receives a record for each NVP request and response. It is
+	// satisfied by *slog.Logger.
+	Logger interface {
+		Log(ctx context.Context, level slog.Level, msg string, args ...any)
+	}
+)
+
+var credentialFields = map[string]bool{
+	userField:      true,
+	passwordField:  true,
+	signatureField: true,
+	subjectField:   true,
+}
+
+var piiFields = map[string]bool{
+	"EMAIL": true,
+}
+
+var piiFieldPrefixes = []string{
+	"L_EMAIL",
+	"L_RECEIVERPHONE",
+	"L_RECEIVERID",
+	"SHIPTO",
+}
+
+// Redact returns a copy of data with the credentials masked and, if pii is
+// set, the receivers of mass payment items and the buyer email and
+// shipping addresses of express checkouts.
+func Redact(data url.Values, pii bool) url.Values {
+	redacted := make(url.Values, len(data))
+	for field, values := range data {
+		if credentialFields[field] || (pii && piiField(field)) {
+			values = []string{Redacted}
+		}
+
+		redacted[field] = values
+	}
+
+	return redacted
+}
+
+func piiField(field string) bool {
+	if piiFields[field] {
+		return true
+	}
+
+	if strings.HasPrefix(field, paymentRequestPrefix) {
+		request := strings.TrimPrefix(field, paymentRequestPrefix)
+		if separator := strings.Index(request, "_"); separator >= 0 {
+			field = request[separator+1:]
+		}
+	}
+
+	for _, prefix := range piiFieldPrefixes {
+		if strings.HasPrefix(field, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Client) logRequest(ctx context.Context, data url.Values, attempt int) {
+	if c.Logger == nil {
+		return
+	}
+
+	c.Logger.Log(
+		ctx,
+		slog.LevelDebug,
+		"NVP request",
+		"method", data.Get(methodField),
+		"endpoint", c.generateEndpoint(),
+		"attempt", attempt,
+		"request", Redact(data, c.RedactPII).Encode(),
+	)
+}
+
+func (c Client) logResponse(ctx context.Context, data url.Values, attempt int, duration time.Duration, response *Response, err error) {
+	if c.Logger == nil {
+		return
+	}
+
+	args := []any{
+		"method", data.Get(methodField),
+		"endpoint", c.generateEndpoint(),
+		"attempt", attempt,
+		"duration", duration,
+	}
+
+	if response == nil {
+		c.Logger.Log(ctx, slog.LevelError, "NVP request failed", append(args, "error", err.Error())...)
+		return
+	}
+
+	args = append(
+		args,
+		"status", response.StatusCode,
+		"ack", string(response.Acknowledgement),
+		"correlation_id", response.CorrelationID,
+	)
+
+	level := slog.LevelInfo
+	switch {
+	case err != nil || !response.Successful():
+		level = slog.LevelError
+	case response.IsWarning():
+		level = slog.LevelWarn
+	}
+
+	if err != nil {
+		args = append(args, "error", err.Error())
+	}
+
+	if codes := responseErrorCodes(response.Errors); len(codes) > 0 {
+		args = append(args, "error_codes", codes)
+	}
+
+	if codes := responseErrorCodes(response.Warnings); len(codes) > 0 {
+		args = append(args, "warning_codes", codes)
+	}
+
+	c.Logger.Log(ctx, level, "NVP response", args...)
+}
+
+func responseErrorCodes(responseErrors []ResponseError) []string {
+	codes := make([]string, len(responseErrors))
+	for i, responseError := range responseErrors {
+		codes[i] = responseError.Code
+	}
+
+	return codes
+}
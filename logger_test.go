@@ -0,0 +1,144 @@
+package paypalnvp_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+var _ paypalnvp.Logger = (*slog.Logger)(nil)
+
+func NewBufferLogger(buffer *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestLogger(t *testing.T) {
+	massPay := SerializedDataMock{
+		mockSerialize: func() (url.Values, error) {
+			return url.Values{
+				"METHOD":           {"MassPay"},
+				"L_EMAIL0":         {"receiver@example.com"},
+				"L_RECEIVERPHONE1": {"07700900123"},
+			}, nil
+		},
+	}
+
+	t.Run(".Execute", func(t *testing.T) {
+		t.Run("LogsRequestAndResponse", func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					return NewMockResponse([]byte(`ACK=Failure&CORRELATIONID=5be53331d9700&L_ERRORCODE0=10321&L_SEVERITYCODE0=Error`))
+				},
+			}
+			client, _ := paypalnvp.NewClientWithCredentials(mockClient, paypalnvp.Sandbox, paypalnvp.Credentials{
+				User:      "api-user",
+				Password:  "secret-password",
+				Signature: "secret-signature",
+				Subject:   "merchant@example.com",
+			})
+			client.Logger = NewBufferLogger(buffer)
+
+			client.Execute(massPay)
+			output := buffer.String()
+
+			for _, expected := range []string{
+				"method=MassPay",
+				"endpoint=https://api-3t.sandbox.paypal.com/nvp",
+				"level=ERROR",
+				"ack=Failure",
+				"correlation_id=5be53331d9700",
+				"error_codes=[10321]",
+				"status=200",
+				"duration=",
+				"receiver%40example.com",
+			} {
+				if !strings.Contains(output, expected) {
+					t.Fatalf("Expected log to contain '%s', got: %s", expected, output)
+				}
+			}
+
+			for _, secret := range []string{"api-user", "secret-password", "secret-signature", "merchant%40example.com"} {
+				if strings.Contains(output, secret) {
+					t.Fatalf("Expected '%s' to be redacted, got: %s", secret, output)
+				}
+			}
+		})
+
+		t.Run("RedactsPII", func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			client, _ := paypalnvp.NewClient(MockClient{}, paypalnvp.Sandbox, "user", "password", "signature")
+			client.Logger = NewBufferLogger(buffer)
+			client.RedactPII = true
+
+			client.Execute(massPay)
+			output := buffer.String()
+
+			if strings.Contains(output, "receiver%40example.com") || strings.Contains(output, "07700900123") {
+				t.Fatalf("Expected receivers to be redacted, got: %s", output)
+			}
+		})
+
+		t.Run("LogsTransportErrors", func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					return nil, errors.New("Connection reset")
+				},
+			}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.Logger = NewBufferLogger(buffer)
+
+			client.Execute(massPay)
+			output := buffer.String()
+
+			if !strings.Contains(output, `msg="NVP request failed"`) || !strings.Contains(output, `error="Connection reset"`) {
+				t.Fatalf("Expected transport error to be logged, got: %s", output)
+			}
+		})
+	})
+
+	t.Run("Redact", func(t *testing.T) {
+		t.Run("RedactsPII", func(t *testing.T) {
+			data := url.Values{
+				"L_EMAIL0":                        {"receiver@example.com"},
+				"L_RECEIVERPHONE0":                {"07700900123"},
+				"L_RECEIVERID0":                   {"ABCDEFGHIJ123"},
+				"EMAIL":                           {"buyer@example.com"},
+				"PAYMENTREQUEST_0_SHIPTONAME":     {"Buyer"},
+				"PAYMENTREQUEST_1_SHIPTOPHONENUM": {"07700900123"},
+				"EMAILSUBJECT":                    {"Payment"},
+				"PAYMENTREQUEST_0_AMT":            {"10.00"},
+			}
+
+			redacted := paypalnvp.Redact(data, true)
+
+			for field := range data {
+				expected := field != "EMAILSUBJECT" && field != "PAYMENTREQUEST_0_AMT"
+				if (redacted.Get(field) == paypalnvp.Redacted) != expected {
+					t.Fatalf("Expected %s redacted to be %t, got: '%s'", field, expected, redacted.Get(field))
+				}
+			}
+		})
+
+		t.Run("LeavesOriginalUnchanged", func(t *testing.T) {
+			data := url.Values{"PWD": {"password"}, "METHOD": {"MassPay"}}
+
+			redacted := paypalnvp.Redact(data, false)
+
+			if redacted.Get("PWD") != paypalnvp.Redacted || redacted.Get("METHOD") != "MassPay" {
+				t.Fatalf("Expected PWD to be redacted, got: %v", redacted)
+			}
+
+			if data.Get("PWD") != "password" {
+				t.Fatalf("Expected original values to be unchanged, got: %v", data)
+			}
+		})
+	})
+}
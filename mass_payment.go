@@ -0,0 +1,148 @@
+package paypalnvp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+type (
+	// MassPayResponse struct for response from a MassPay request.
+	MassPayResponse struct {
+		Response
+	}
+
+	// MassPaymentBatcher executes mass payments above the MassPay item limit
+	// by splitting them into batches, each sent as its own MassPay call.
+	MassPaymentBatcher struct {
+		Client *Client
+
+		// BatchSize maximum number of items in a batch,
+		// payload.MaxMassPaymentItems if zero.
+		BatchSize int
+
+		// Concurrency number of batches executed at once, one if zero.
+		Concurrency int
+	}
+
+	// MassPaymentBatch a batch of a mass payment and the outcome of
+	// executing it.
+	MassPaymentBatch struct {
+		MassPayment *payload.MassPayment
+
+		// Offset index in the original mass payment of the first item in
+		// the batch.
+		Offset int
+
+		Response *Response
+		Err      error
+	}
+
+	// MassPaymentBatchResult outcome of every batch of a mass payment, in
+	// the order of their items.
+	MassPaymentBatchResult struct {
+		Batches []MassPaymentBatch
+
+		// Err set if no batch was executed as the mass payment is invalid,
+		// see payload.MassPayment.ValidateSplit.
+		Err error
+	}
+)
+
+// NewMassPaymentBatcher creates a new MassPaymentBatcher executing batches
+// of the maximum size one at a time.
+func NewMassPaymentBatcher(client *Client) *MassPaymentBatcher {
+	return &MassPaymentBatcher{Client: client}
+}
+
+// Execute splits the mass payment into batches and executes them.
+func (b MassPaymentBatcher) Execute(massPayment *payload.MassPayment) MassPaymentBatchResult {
+	return b.ExecuteContext(context.Background(), massPayment)
+}
+
+// ExecuteContext splits the mass payment into batches and executes them
+// with ctx. A failed batch does not stop the others, and is not retried, as
+// PayPal may have paid some of its items; check the result for failures.
+// Batches not started before ctx is done fail with its error. Invalid mass
+// payments are not split, and are returned with the validation error.
+func (b MassPaymentBatcher) ExecuteContext(ctx context.Context, massPayment *payload.MassPayment) MassPaymentBatchResult {
+	concurrency := b.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	result := MassPaymentBatchResult{}
+	if err := massPayment.ValidateSplit(); err != nil {
+		result.Err = b.Client.serializationError(err)
+		return result
+	}
+
+	offset := 0
+	for _, batch := range massPayment.Split(b.BatchSize) {
+		result.Batches = append(result.Batches, MassPaymentBatch{MassPayment: batch, Offset: offset})
+		offset += len(batch.Items)
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i := range result.Batches {
+		if ctx.Err() != nil {
+			result.Batches[i].Err = ctx.Err()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Batches[i].Err = ctx.Err()
+			continue
+		case slots <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(batch *MassPaymentBatch) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			batch.Response, batch.Err = b.Client.ExecuteContext(ctx, batch.MassPayment)
+		}(&result.Batches[i])
+	}
+	wg.Wait()
+
+	return result
+}
+
+// Successful indicates if the batch was executed and PayPal accepted it.
+func (mpb MassPaymentBatch) Successful() bool {
+	return mpb.Err == nil && mpb.Response != nil && mpb.Response.Successful()
+}
+
+// Successful indicates if the mass payment was executed and every batch
+// was accepted.
+func (r MassPaymentBatchResult) Successful() bool {
+	return r.Err == nil && len(r.Batches) > 0 && len(r.Failed()) == 0
+}
+
+// Failed returns the batches that were not accepted.
+func (r MassPaymentBatchResult) Failed() []MassPaymentBatch {
+	failed := []MassPaymentBatch{}
+	for _, batch := range r.Batches {
+		if !batch.Successful() {
+			failed = append(failed, batch)
+		}
+	}
+
+	return failed
+}
+
+// BatchOf returns the batch containing the item at index in the original
+// mass payment.
+func (r MassPaymentBatchResult) BatchOf(index int) (MassPaymentBatch, bool) {
+	for _, batch := range r.Batches {
+		if index >= batch.Offset && index < batch.Offset+len(batch.MassPayment.Items) {
+			return batch, true
+		}
+	}
+
+	return MassPaymentBatch{}, false
+}
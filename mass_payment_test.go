@@ -0,0 +1,147 @@
+package paypalnvp_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+func NewLargeMassPayment(items int) *payload.MassPayment {
+	massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+	for i := 0; i < items; i++ {
+		massPayment.AddItem(payload.MassPaymentItem{
+			Email:  "creator@example.com",
+			Amount: money.New(100, "GBP"),
+		})
+	}
+
+	return massPayment
+}
+
+func TestMassPaymentBatcher(t *testing.T) {
+	t.Run(".Execute()", func(t *testing.T) {
+		t.Run("SplitsIntoCompliantBatches", func(t *testing.T) {
+			var mu sync.Mutex
+			itemCounts := []int{}
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					body, _ := ioutil.ReadAll(request.Body)
+					data, _ := url.ParseQuery(string(body))
+
+					mu.Lock()
+					itemCounts = append(itemCounts, countIndexed(data, "L_AMT"))
+					mu.Unlock()
+
+					return NewMockResponse([]byte(`ACK=Success`))
+				},
+			}
+
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			batcher := paypalnvp.NewMassPaymentBatcher(client)
+			batcher.Concurrency = 2
+			result := batcher.Execute(NewLargeMassPayment(600))
+
+			if !result.Successful() || len(result.Batches) != 3 {
+				t.Fatalf("Expected 3 successful batches, got: %d, failed: %d", len(result.Batches), len(result.Failed()))
+			}
+
+			total := 0
+			for _, count := range itemCounts {
+				if count > payload.MaxMassPaymentItems {
+					t.Fatalf("Expected at most %d items per call, got: %d", payload.MaxMassPaymentItems, count)
+				}
+				total += count
+			}
+
+			if total != 600 {
+				t.Fatalf("Expected 600 items to be sent, got: %d", total)
+			}
+		})
+
+		t.Run("ReportsFailedBatches", func(t *testing.T) {
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					body, _ := ioutil.ReadAll(request.Body)
+					data, _ := url.ParseQuery(string(body))
+
+					if data.Get("L_UNIQUEID0") == "fail" {
+						return NewMockResponse([]byte(`ACK=Failure&L_ERRORCODE0=10321&L_SEVERITYCODE0=Error`))
+					}
+
+					return NewMockResponse([]byte(`ACK=Success`))
+				},
+			}
+
+			massPayment := NewLargeMassPayment(5)
+			massPayment.Items[2].ID = "fail"
+
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			batcher := paypalnvp.NewMassPaymentBatcher(client)
+			batcher.BatchSize = 2
+			result := batcher.Execute(massPayment)
+
+			failed := result.Failed()
+			if result.Successful() || len(failed) != 1 || failed[0].Offset != 2 {
+				t.Fatalf("Expected the second batch to fail, got: %+v", failed)
+			}
+
+			if batch, ok := result.BatchOf(3); !ok || batch.Offset != 2 {
+				t.Fatalf("Expected item 3 to be in the batch at offset 2, got: %+v", batch)
+			}
+		})
+
+		t.Run("ReturnsErrorForInvalidMassPayment", func(t *testing.T) {
+			calls := 0
+			httpClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					calls++
+					return NewMockResponse([]byte(`ACK=Success`))
+				},
+			}
+
+			client, _ := paypalnvp.NewClient(httpClient, paypalnvp.Sandbox, "user", "password", "signature")
+			result := paypalnvp.NewMassPaymentBatcher(client).Execute(payload.NewMassPayment("USD", payload.ReceiverTypeEmail))
+
+			if result.Successful() || result.Err == nil || len(result.Batches) != 0 || calls != 0 {
+				t.Fatalf("Expected unsuccessful result with an error and no calls, got: %+v, %d calls", result, calls)
+			}
+
+			var validationErrors payload.ValidationErrors
+			if !errors.As(result.Err, &validationErrors) {
+				t.Fatalf("Expected ValidationErrors, got: %v", result.Err)
+			}
+		})
+
+		t.Run("FailsBatchesNotStartedWhenCancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			client, _ := paypalnvp.NewClient(MockClient{}, paypalnvp.Sandbox, "user", "password", "signature")
+			result := paypalnvp.NewMassPaymentBatcher(client).ExecuteContext(ctx, NewLargeMassPayment(300))
+
+			if len(result.Failed()) != 2 {
+				t.Fatalf("Expected 2 failed batches, got: %d", len(result.Failed()))
+			}
+		})
+	})
+}
+
+func countIndexed(data url.Values, prefix string) int {
+	count := 0
+	for key := range data {
+		if strings.HasPrefix(key, prefix) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,69 @@
+// Package metrics records request counts, latencies, ACK outcomes and
+// error codes of NVP calls, through a Collector installed as a client
+// interceptor.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+const (
+	// OutcomeError acknowledgement recorded for calls which failed without
+	// a response, e.g. transport errors.
+	OutcomeError = "Error"
+)
+
+type (
+	// Collector receives an observation of each call. Implementations must
+	// be safe for concurrent use.
+	Collector interface {
+		Observe(observation Observation)
+	}
+
+	// Observation of a single call.
+	Observation struct {
+		// Method NVP method of the call.
+		Method string
+
+		// Duration of the call, including any retries.
+		Duration time.Duration
+
+		// Acknowledgement ACK of the response, OutcomeError if there is no
+		// response.
+		Acknowledgement string
+
+		// ErrorCodes codes of the errors PayPal returned, excluding
+		// warnings.
+		ErrorCodes []string
+	}
+)
+
+// Interceptor returns an interceptor passing an observation of each call
+// to collector, e.g.
+//
+//	client.Interceptors = append(client.Interceptors, metrics.Interceptor(registry))
+func Interceptor(collector Collector) paypalnvp.Interceptor {
+	return func(ctx context.Context, call *paypalnvp.Call, next paypalnvp.Invoker) (*paypalnvp.Response, error) {
+		start := time.Now()
+		response, err := next(ctx, call)
+
+		observation := Observation{
+			Method:          call.Method(),
+			Duration:        time.Since(start),
+			Acknowledgement: OutcomeError,
+		}
+
+		if response != nil {
+			observation.Acknowledgement = string(response.Acknowledgement)
+			for _, responseError := range response.Errors {
+				observation.ErrorCodes = append(observation.ErrorCodes, responseError.Code)
+			}
+		}
+
+		collector.Observe(observation)
+		return response, err
+	}
+}
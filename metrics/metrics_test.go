@@ -0,0 +1,102 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp/metrics"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+	"github.com/vidsy/go-paypalnvp/paypalnvptest"
+)
+
+func NewMassPayment(amount string) *payload.MassPayment {
+	massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+	massPayment.AddItem(payload.MassPaymentItem{
+		Email:  "receiver@example.com",
+		Amount: money.MustParse(amount, "GBP"),
+	})
+
+	return massPayment
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("Interceptor", func(t *testing.T) {
+		t.Run("RecordsCalls", func(t *testing.T) {
+			server := paypalnvptest.NewServer()
+			defer server.Close()
+			server.SetBalance(money.MustParse("15.00", "GBP"))
+
+			registry := metrics.NewRegistry()
+			client := server.NewClient()
+			client.Interceptors = append(client.Interceptors, metrics.Interceptor(registry))
+
+			client.Execute(NewMassPayment("10.00"))
+			client.Execute(NewMassPayment("10.00"))
+			client.Execute(payload.NewGetBalance(false))
+
+			if registry.Requests("MassPay", "Success") != 1 || registry.Requests("MassPay", "Failure") != 1 {
+				t.Fatalf("Expected 1 successful and 1 failed MassPay, got: %d, %d", registry.Requests("MassPay", "Success"), registry.Requests("MassPay", "Failure"))
+			}
+
+			if registry.Errors("MassPay", "10321") != 1 {
+				t.Fatalf("Expected 1 error with code 10321, got: %d", registry.Errors("MassPay", "10321"))
+			}
+
+			if registry.Latency("MassPay").Count != 2 || registry.Latency("GetBalance").Count != 1 {
+				t.Fatalf("Expected latencies per method, got: %+v, %+v", registry.Latency("MassPay"), registry.Latency("GetBalance"))
+			}
+		})
+
+		t.Run("RecordsTransportErrors", func(t *testing.T) {
+			server := paypalnvptest.NewServer()
+			server.Close()
+
+			registry := metrics.NewRegistry()
+			client := server.NewClient()
+			client.Interceptors = append(client.Interceptors, metrics.Interceptor(registry))
+
+			client.Execute(payload.NewGetBalance(false))
+
+			if registry.Requests("GetBalance", metrics.OutcomeError) != 1 {
+				t.Fatalf("Expected 1 failed GetBalance, got: %d", registry.Requests("GetBalance", metrics.OutcomeError))
+			}
+		})
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		t.Run("BucketsLatencies", func(t *testing.T) {
+			registry := metrics.NewRegistry(1, 0.5)
+			for _, duration := range []time.Duration{100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+				registry.Observe(metrics.Observation{Method: "MassPay", Duration: duration})
+			}
+
+			histogram := registry.Latency("MassPay")
+			if histogram.Buckets[0] != 0.5 || histogram.Counts[0] != 2 || histogram.Counts[1] != 0 || histogram.Counts[2] != 1 {
+				t.Fatalf("Expected counts [2 0 1] for buckets [0.5 1], got: %v for %v", histogram.Counts, histogram.Buckets)
+			}
+
+			if histogram.Sum != 2.6 || histogram.Count != 3 {
+				t.Fatalf("Expected sum 2.6 of 3 observations, got: %v of %d", histogram.Sum, histogram.Count)
+			}
+		})
+
+		t.Run("LatencyReturnsCopyOfBuckets", func(t *testing.T) {
+			registry := metrics.NewRegistry(1, 0.5)
+			registry.Observe(metrics.Observation{Method: "MassPay", Duration: 100 * time.Millisecond})
+			registry.Latency("MassPay").Buckets[0] = 10
+			registry.Latency("GetBalance").Buckets[0] = 10
+
+			registry.Observe(metrics.Observation{Method: "GetBalance", Duration: 2 * time.Second})
+
+			histogram := registry.Latency("GetBalance")
+			if histogram.Buckets[0] != 0.5 || histogram.Counts[2] != 1 {
+				t.Fatalf("Expected buckets [0.5 1] with a count above them, got: %v for %v", histogram.Counts, histogram.Buckets)
+			}
+
+			if registry.Latency("MassPay").Buckets[0] != 0.5 {
+				t.Fatalf("Expected MassPay buckets [0.5 1], got: %v", registry.Latency("MassPay").Buckets)
+			}
+		})
+	})
+}
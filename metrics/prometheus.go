@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PrometheusContentType content type of the Prometheus text exposition
+	// format.
+	PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	requestsMetric = "paypalnvp_requests_total"
+	errorsMetric   = "paypalnvp_errors_total"
+	latencyMetric  = "paypalnvp_request_duration_seconds"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the registry to w in the Prometheus text
+// exposition format, as paypalnvp_requests_total{method,ack},
+// paypalnvp_errors_total{method,code} and the
+// paypalnvp_request_duration_seconds{method} histogram. The registry is
+// copied before writing, so a slow writer does not block Observe.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	requests, errs, latencies := r.snapshot()
+	buffer := bufio.NewWriter(w)
+
+	fmt.Fprintf(buffer, "# HELP %s NVP calls by method and ACK.\n", requestsMetric)
+	fmt.Fprintf(buffer, "# TYPE %s counter\n", requestsMetric)
+	for _, key := range sortedRequestKeys(requests) {
+		fmt.Fprintf(buffer, "%s{method=\"%s\",ack=\"%s\"} %d\n", requestsMetric, escape(key.method), escape(key.acknowledgement), requests[key])
+	}
+
+	fmt.Fprintf(buffer, "# HELP %s Errors returned by PayPal by method and error code.\n", errorsMetric)
+	fmt.Fprintf(buffer, "# TYPE %s counter\n", errorsMetric)
+	for _, key := range sortedErrorKeys(errs) {
+		fmt.Fprintf(buffer, "%s{method=\"%s\",code=\"%s\"} %d\n", errorsMetric, escape(key.method), escape(key.code), errs[key])
+	}
+
+	fmt.Fprintf(buffer, "# HELP %s Duration of NVP calls by method.\n", latencyMetric)
+	fmt.Fprintf(buffer, "# TYPE %s histogram\n", latencyMetric)
+	for _, method := range sortedMethods(latencies) {
+		histogram := latencies[method]
+		cumulative := uint64(0)
+		for i, bound := range histogram.Buckets {
+			cumulative += histogram.Counts[i]
+			fmt.Fprintf(buffer, "%s_bucket{method=\"%s\",le=\"%s\"} %d\n", latencyMetric, escape(method), formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(buffer, "%s_bucket{method=\"%s\",le=\"+Inf\"} %d\n", latencyMetric, escape(method), histogram.Count)
+		fmt.Fprintf(buffer, "%s_sum{method=\"%s\"} %s\n", latencyMetric, escape(method), formatFloat(histogram.Sum))
+		fmt.Fprintf(buffer, "%s_count{method=\"%s\"} %d\n", latencyMetric, escape(method), histogram.Count)
+	}
+
+	return buffer.Flush()
+}
+
+// PrometheusHandler returns an http.Handler serving the registry in the
+// Prometheus text exposition format, for scraping.
+func PrometheusHandler(registry *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		registry.WritePrometheus(w)
+	})
+}
+
+func sortedRequestKeys(requests map[requestKey]uint64) []requestKey {
+	keys := make([]requestKey, 0, len(requests))
+	for key := range requests {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+
+		return keys[i].acknowledgement < keys[j].acknowledgement
+	})
+
+	return keys
+}
+
+func sortedErrorKeys(errs map[errorKey]uint64) []errorKey {
+	keys := make([]errorKey, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+
+		return keys[i].code < keys[j].code
+	})
+
+	return keys
+}
+
+func sortedMethods(latencies map[string]Histogram) []string {
+	methods := make([]string, 0, len(latencies))
+	for method := range latencies {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	return methods
+}
+
+func escape(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
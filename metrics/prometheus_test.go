@@ -0,0 +1,106 @@
+package metrics_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp/metrics"
+)
+
+type BlockingWriter struct {
+	once    sync.Once
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (bw *BlockingWriter) Write(p []byte) (int, error) {
+	bw.once.Do(func() { close(bw.writing) })
+	<-bw.release
+
+	return len(p), nil
+}
+
+func TestPrometheus(t *testing.T) {
+	registry := metrics.NewRegistry(0.5, 1)
+	registry.Observe(metrics.Observation{Method: "MassPay", Duration: 250 * time.Millisecond, Acknowledgement: "Success"})
+	registry.Observe(metrics.Observation{Method: "MassPay", Duration: 750 * time.Millisecond, Acknowledgement: "Failure", ErrorCodes: []string{"10321"}})
+	registry.Observe(metrics.Observation{Method: `Get"Balance`, Duration: time.Second, Acknowledgement: "Success"})
+
+	t.Run(".WritePrometheus", func(t *testing.T) {
+		t.Run("WritesTextExposition", func(t *testing.T) {
+			buffer := &bytes.Buffer{}
+			registry.WritePrometheus(buffer)
+
+			expected := `# HELP paypalnvp_requests_total NVP calls by method and ACK.
+# TYPE paypalnvp_requests_total counter
+paypalnvp_requests_total{method="Get\"Balance",ack="Success"} 1
+paypalnvp_requests_total{method="MassPay",ack="Failure"} 1
+paypalnvp_requests_total{method="MassPay",ack="Success"} 1
+# HELP paypalnvp_errors_total Errors returned by PayPal by method and error code.
+# TYPE paypalnvp_errors_total counter
+paypalnvp_errors_total{method="MassPay",code="10321"} 1
+# HELP paypalnvp_request_duration_seconds Duration of NVP calls by method.
+# TYPE paypalnvp_request_duration_seconds histogram
+paypalnvp_request_duration_seconds_bucket{method="Get\"Balance",le="0.5"} 0
+paypalnvp_request_duration_seconds_bucket{method="Get\"Balance",le="1"} 1
+paypalnvp_request_duration_seconds_bucket{method="Get\"Balance",le="+Inf"} 1
+paypalnvp_request_duration_seconds_sum{method="Get\"Balance"} 1
+paypalnvp_request_duration_seconds_count{method="Get\"Balance"} 1
+paypalnvp_request_duration_seconds_bucket{method="MassPay",le="0.5"} 1
+paypalnvp_request_duration_seconds_bucket{method="MassPay",le="1"} 2
+paypalnvp_request_duration_seconds_bucket{method="MassPay",le="+Inf"} 2
+paypalnvp_request_duration_seconds_sum{method="MassPay"} 1
+paypalnvp_request_duration_seconds_count{method="MassPay"} 2
+`
+			if buffer.String() != expected {
+				t.Fatalf("Expected:\n%s\ngot:\n%s", expected, buffer.String())
+			}
+		})
+	})
+
+	t.Run(".WritePrometheus", func(t *testing.T) {
+		t.Run("DoesNotBlockObserveWhileWriting", func(t *testing.T) {
+			registry := metrics.NewRegistry()
+			for i := 0; i < 100; i++ {
+				registry.Observe(metrics.Observation{Method: fmt.Sprintf("Method%d", i), Acknowledgement: "Success"})
+			}
+
+			writer := &BlockingWriter{writing: make(chan struct{}), release: make(chan struct{})}
+			go registry.WritePrometheus(writer)
+			defer close(writer.release)
+			<-writer.writing
+
+			observed := make(chan struct{})
+			go func() {
+				registry.Observe(metrics.Observation{Method: "MassPay", Acknowledgement: "Success"})
+				close(observed)
+			}()
+
+			select {
+			case <-observed:
+			case <-time.After(time.Second):
+				t.Fatalf("Expected Observe not to wait for the writer")
+			}
+		})
+	})
+
+	t.Run("PrometheusHandler", func(t *testing.T) {
+		t.Run("ServesTextExposition", func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			metrics.PrometheusHandler(registry).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+			if recorder.Header().Get("Content-Type") != metrics.PrometheusContentType {
+				t.Fatalf("Expected content type '%s', got: '%s'", metrics.PrometheusContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !strings.Contains(recorder.Body.String(), `paypalnvp_errors_total{method="MassPay",code="10321"} 1`) {
+				t.Fatalf("Expected error counter, got: %s", recorder.Body.String())
+			}
+		})
+	})
+}
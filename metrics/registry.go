@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+)
+
+// DefaultBuckets upper bounds, in seconds, of the latency histogram
+// buckets used by NewRegistry when none are given.
+var DefaultBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type (
+	// Registry in-memory Collector keeping counters and latency histograms
+	// per NVP method.
+	Registry struct {
+		buckets []float64
+
+		mutex     sync.Mutex
+		requests  map[requestKey]uint64
+		errors    map[errorKey]uint64
+		latencies map[string]*Histogram
+	}
+
+	// Histogram latency distribution of a method.
+	Histogram struct {
+		// Buckets upper bounds, in seconds, of the buckets.
+		Buckets []float64
+
+		// Counts number of observations in each bucket, not cumulative.
+		// The last count is of observations above every bucket.
+		Counts []uint64
+
+		// Sum total of the observations in seconds.
+		Sum float64
+
+		// Count number of observations.
+		Count uint64
+	}
+
+	requestKey struct {
+		method          string
+		acknowledgement string
+	}
+
+	errorKey struct {
+		method string
+		code   string
+	}
+)
+
+// NewRegistry Creates a new registry with latency histograms using
+// buckets, in seconds, or DefaultBuckets if none are given.
+func NewRegistry(buckets ...float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	return &Registry{
+		buckets:   sorted,
+		requests:  map[requestKey]uint64{},
+		errors:    map[errorKey]uint64{},
+		latencies: map[string]*Histogram{},
+	}
+}
+
+// Observe records the observation.
+func (r *Registry) Observe(observation Observation) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.requests[requestKey{observation.Method, observation.Acknowledgement}]++
+	for _, code := range observation.ErrorCodes {
+		r.errors[errorKey{observation.Method, code}]++
+	}
+
+	histogram, ok := r.latencies[observation.Method]
+	if !ok {
+		histogram = &Histogram{
+			Buckets: r.buckets,
+			Counts:  make([]uint64, len(r.buckets)+1),
+		}
+		r.latencies[observation.Method] = histogram
+	}
+
+	seconds := observation.Duration.Seconds()
+	histogram.Counts[sort.SearchFloat64s(r.buckets, seconds)]++
+	histogram.Sum += seconds
+	histogram.Count++
+}
+
+// Requests number of calls of method acknowledged with acknowledgement.
+func (r *Registry) Requests(method string, acknowledgement string) uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.requests[requestKey{method, acknowledgement}]
+}
+
+// Errors number of times PayPal returned the error code for method.
+func (r *Registry) Errors(method string, code string) uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.errors[errorKey{method, code}]
+}
+
+// snapshot copies the counters and histograms, so they can be read without
+// holding the lock.
+func (r *Registry) snapshot() (map[requestKey]uint64, map[errorKey]uint64, map[string]Histogram) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	requests := make(map[requestKey]uint64, len(r.requests))
+	for key, count := range r.requests {
+		requests[key] = count
+	}
+
+	errs := make(map[errorKey]uint64, len(r.errors))
+	for key, count := range r.errors {
+		errs[key] = count
+	}
+
+	latencies := make(map[string]Histogram, len(r.latencies))
+	for method, histogram := range r.latencies {
+		latencies[method] = histogram.copy()
+	}
+
+	return requests, errs, latencies
+}
+
+// Latency copy of the latency histogram of method, with no observations
+// if the method has not been called.
+func (r *Registry) Latency(method string) Histogram {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	histogram, ok := r.latencies[method]
+	if !ok {
+		return Histogram{
+			Buckets: append([]float64(nil), r.buckets...),
+			Counts:  make([]uint64, len(r.buckets)+1),
+		}
+	}
+
+	return histogram.copy()
+}
+
+func (h Histogram) copy() Histogram {
+	h.Buckets = append([]float64(nil), h.Buckets...)
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
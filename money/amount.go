@@ -0,0 +1,230 @@
+// Package money provides a decimal amount type for PayPal payments which
+// avoids the rounding drift of floats.
+package money
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vidsy/go-paypalnvp/currency"
+)
+
+const (
+	maxDecimals = 2
+	scale       = 100
+)
+
+type (
+	// Amount monetary amount stored as an integer number of hundredths. The
+	// currency, when known, decides how many decimal places the amount is
+	// formatted with, as PayPal rejects decimals for currencies such as JPY.
+	// The zero value is zero with no currency.
+	Amount struct {
+		hundredths int64
+		currency   string
+	}
+)
+
+// New creates an amount from a number of minor units of the currency,
+// e.g. pence for GBP or yen for JPY.
+func New(minorUnits int64, currencyCode string) Amount {
+	if Decimals(currencyCode) == 0 {
+		minorUnits *= scale
+	}
+
+	return Amount{hundredths: minorUnits, currency: currencyCode}
+}
+
+// Parse parses a decimal string such as "10.50" into an amount in the
+// currency, returning an error if it has more decimal places than the
+// currency allows.
+func Parse(value string, currencyCode string) (Amount, error) {
+	trimmed := strings.TrimSpace(value)
+	negative := strings.HasPrefix(trimmed, "-")
+	trimmed = strings.TrimPrefix(trimmed, "-")
+
+	whole, fraction := trimmed, ""
+	if i := strings.Index(trimmed, "."); i >= 0 {
+		whole, fraction = trimmed[:i], trimmed[i+1:]
+	}
+
+	if whole == "" || strings.ContainsAny(whole, "+-") || strings.ContainsAny(fraction, "+-") {
+		return Amount{}, fmt.Errorf("Invalid amount '%s'", value)
+	}
+
+	if len(fraction) > maxDecimals {
+		return Amount{}, fmt.Errorf("Invalid amount '%s', expected at most %d decimal places", value, maxDecimals)
+	}
+
+	fraction += strings.Repeat("0", maxDecimals-len(fraction))
+	hundredths, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Amount{}, fmt.Errorf("Invalid amount '%s'", value)
+	}
+
+	if negative {
+		hundredths = -hundredths
+	}
+
+	amount := Amount{hundredths: hundredths, currency: currencyCode}
+	if !amount.fitsCurrency() {
+		return Amount{}, fmt.Errorf("Invalid amount '%s', %s does not support decimal places", value, currencyCode)
+	}
+
+	return amount, nil
+}
+
+// MustParse parses a decimal string like Parse, panicking on error. It is
+// intended for constants and tests.
+func MustParse(value string, currencyCode string) Amount {
+	amount, err := Parse(value, currencyCode)
+	if err != nil {
+		panic(err)
+	}
+
+	return amount
+}
+
+// Decimals number of decimal places PayPal accepts for the currency, see
+// currency.Decimals.
+func Decimals(currencyCode string) int {
+	return currency.Decimals(currencyCode)
+}
+
+// Currency currency code of the amount, empty if unknown.
+func (a Amount) Currency() string {
+	return a.currency
+}
+
+// WithCurrency returns the amount in the given currency.
+func (a Amount) WithCurrency(currencyCode string) Amount {
+	a.currency = currencyCode
+	return a
+}
+
+// MinorUnits amount as a number of minor units of its currency, truncating
+// any fraction the currency does not support.
+func (a Amount) MinorUnits() int64 {
+	if Decimals(a.currency) == 0 {
+		return a.hundredths / scale
+	}
+
+	return a.hundredths
+}
+
+// Add returns the sum of the amounts, in the currency of a, or of b if a
+// has none. Currencies are not checked.
+func (a Amount) Add(b Amount) Amount {
+	if a.currency == "" {
+		a.currency = b.currency
+	}
+	a.hundredths += b.hundredths
+
+	return a
+}
+
+// Sub returns the difference of the amounts, in the currency of a, or of b
+// if a has none. Currencies are not checked.
+func (a Amount) Sub(b Amount) Amount {
+	return a.Add(Amount{hundredths: -b.hundredths, currency: b.currency})
+}
+
+// Cmp compares the values of the amounts, returning -1, 0 or +1 as a is
+// less than, equal to or greater than b.
+func (a Amount) Cmp(b Amount) int {
+	switch {
+	case a.hundredths < b.hundredths:
+		return -1
+	case a.hundredths > b.hundredths:
+		return 1
+	}
+
+	return 0
+}
+
+// IsZero indicates if the value of the amount is zero.
+func (a Amount) IsZero() bool {
+	return a.hundredths == 0
+}
+
+// IsNegative indicates if the value of the amount is below zero.
+func (a Amount) IsNegative() bool {
+	return a.hundredths < 0
+}
+
+// Float64 value of the amount as a float, for display only.
+func (a Amount) Float64() float64 {
+	return float64(a.hundredths) / scale
+}
+
+// String formats the amount with the decimal places of its currency, e.g.
+// "10.50" for GBP or "1050" for JPY.
+func (a Amount) String() string {
+	if Decimals(a.currency) == 0 {
+		return fmt.Sprintf("%d", a.hundredths/scale)
+	}
+
+	return a.decimalString()
+}
+
+// Validate checks the amount against the PayPal rules for its currency,
+// returning an error if the currency is not supported, the amount has more
+// decimal places than the currency allows or it exceeds the maximum for a
+// single transaction.
+func (a Amount) Validate() error {
+	rules, ok := currency.Lookup(a.currency)
+	if !ok {
+		return currency.UnsupportedError{Code: a.currency}
+	}
+
+	if !a.fitsCurrency() {
+		return fmt.Errorf("Amount %s has decimal places not supported by %s", a.decimalString(), a.currency)
+	}
+
+	if a.hundredths > rules.MaxAmount*scale {
+		return fmt.Errorf("Amount %s exceeds the %s maximum of %d", a, a.currency, rules.MaxAmount)
+	}
+
+	return nil
+}
+
+// MarshalNVP formats the amount for an NVP request.
+func (a Amount) MarshalNVP() (string, error) {
+	if !a.fitsCurrency() {
+		return "", fmt.Errorf("Amount %s has decimal places not supported by %s", a.decimalString(), a.currency)
+	}
+
+	return a.String(), nil
+}
+
+// UnmarshalNVP parses an amount from an NVP response, keeping the current
+// currency. An empty value leaves the amount unset.
+func (a *Amount) UnmarshalNVP(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	amount, err := Parse(value, "")
+	if err != nil {
+		return err
+	}
+
+	a.hundredths = amount.hundredths
+	return nil
+}
+
+func (a Amount) decimalString() string {
+	sign := ""
+	hundredths := a.hundredths
+	if hundredths < 0 {
+		sign = "-"
+		hundredths = -hundredths
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, hundredths/scale, hundredths%scale)
+}
+
+func (a Amount) fitsCurrency() bool {
+	return Decimals(a.currency) != 0 || a.hundredths%scale == 0
+}
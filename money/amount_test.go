@@ -0,0 +1,133 @@
+package money_test
+
+import (
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp/money"
+)
+
+func TestAmount(t *testing.T) {
+	t.Run("Parse()", func(t *testing.T) {
+		t.Run("ParsesDecimalString", func(t *testing.T) {
+			amount, err := money.Parse("10.5", "GBP")
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if amount.MinorUnits() != 1050 || amount.Currency() != "GBP" {
+				t.Fatalf("Expected 1050 GBP minor units, got: %d %s", amount.MinorUnits(), amount.Currency())
+			}
+		})
+
+		t.Run("ParsesNegativeAmount", func(t *testing.T) {
+			amount, _ := money.Parse("-0.25", "GBP")
+
+			if !amount.IsNegative() || amount.String() != "-0.25" {
+				t.Fatalf("Expected -0.25, got: %s", amount)
+			}
+		})
+
+		t.Run("ReturnsErrorForTooManyDecimals", func(t *testing.T) {
+			if _, err := money.Parse("10.505", "GBP"); err == nil {
+				t.Fatal("Expected error, got: nil")
+			}
+		})
+
+		t.Run("ReturnsErrorForDecimalsInZeroDecimalCurrency", func(t *testing.T) {
+			if _, err := money.Parse("100.50", "JPY"); err == nil {
+				t.Fatal("Expected error, got: nil")
+			}
+		})
+
+		t.Run("ReturnsErrorForInvalidString", func(t *testing.T) {
+			for _, value := range []string{"", "abc", "1.2.3", "--1", ".50"} {
+				if _, err := money.Parse(value, "GBP"); err == nil {
+					t.Fatalf("Expected error for '%s', got: nil", value)
+				}
+			}
+		})
+	})
+
+	t.Run(".Add()", func(t *testing.T) {
+		t.Run("DoesNotDrift", func(t *testing.T) {
+			total := money.New(0, "GBP")
+			for i := 0; i < 10; i++ {
+				total = total.Add(money.MustParse("0.10", "GBP"))
+			}
+
+			if total != money.MustParse("1.00", "GBP") {
+				t.Fatalf("Expected 1.00, got: %s", total)
+			}
+		})
+
+		t.Run("TakesCurrencyFromOtherWhenUnset", func(t *testing.T) {
+			total := money.Amount{}.Add(money.New(100, "USD"))
+
+			if total.Currency() != "USD" {
+				t.Fatalf("Expected currency USD, got: '%s'", total.Currency())
+			}
+		})
+	})
+
+	t.Run(".String()", func(t *testing.T) {
+		t.Run("FormatsWithCurrencyDecimals", func(t *testing.T) {
+			if amount := money.New(1050, "GBP"); amount.String() != "10.50" {
+				t.Fatalf("Expected 10.50, got: %s", amount)
+			}
+
+			if amount := money.New(1050, "JPY"); amount.String() != "1050" {
+				t.Fatalf("Expected 1050, got: %s", amount)
+			}
+		})
+	})
+
+	t.Run(".Validate()", func(t *testing.T) {
+		t.Run("ReturnsNilForValidAmount", func(t *testing.T) {
+			if err := money.MustParse("5500.00", "GBP").Validate(); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForUnsupportedCurrency", func(t *testing.T) {
+			if err := money.MustParse("10.00", "XYZ").Validate(); err == nil {
+				t.Fatal("Expected error, got: nil")
+			}
+		})
+
+		t.Run("ReturnsErrorAboveTransactionMaximum", func(t *testing.T) {
+			if err := money.MustParse("5500.01", "GBP").Validate(); err == nil {
+				t.Fatal("Expected error, got: nil")
+			}
+		})
+	})
+
+	t.Run(".MarshalNVP()", func(t *testing.T) {
+		t.Run("ReturnsErrorForUnsupportedFraction", func(t *testing.T) {
+			amount := money.MustParse("10.50", "GBP").WithCurrency("JPY")
+
+			if _, err := amount.MarshalNVP(); err == nil {
+				t.Fatal("Expected error, got: nil")
+			}
+		})
+	})
+
+	t.Run(".UnmarshalNVP()", func(t *testing.T) {
+		t.Run("KeepsCurrency", func(t *testing.T) {
+			amount := money.New(0, "GBP")
+			if err := amount.UnmarshalNVP("5.25"); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if amount != money.MustParse("5.25", "GBP") {
+				t.Fatalf("Expected 5.25 GBP, got: %s %s", amount, amount.Currency())
+			}
+		})
+
+		t.Run("IgnoresEmptyValue", func(t *testing.T) {
+			amount := money.New(0, "GBP")
+			if err := amount.UnmarshalNVP(""); err != nil || amount != money.New(0, "GBP") {
+				t.Fatalf("Expected unset amount without error, got: %v, %s", err, amount)
+			}
+		})
+	})
+}
@@ -0,0 +1,260 @@
+package nvp
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type decoder struct {
+	values   url.Values
+	visiting map[reflect.Type]bool
+	err      error
+}
+
+// Unmarshal decodes NVP values into the struct pointed to by v.
+//
+// Keys missing from data leave their fields untouched, indexed slices are
+// replaced with as many elements as there are consecutive indexes present,
+// and empty values for non-string fields are ignored, though Unmarshalers
+// receive them to decide for themselves. Embedded pointers to
+// unexported structs are only decoded into if already set, and nested
+// structs of a type already being decoded are skipped, so recursive types
+// are walked once. Values that cannot be parsed leave their fields
+// untouched, and once every other field is decoded a DecodeError is
+// returned for the first of them.
+func Unmarshal(data url.Values, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+
+	d := &decoder{values: data, visiting: map[reflect.Type]bool{}}
+	if _, err := d.decodeStruct(value.Elem(), nil); err != nil {
+		return err
+	}
+
+	return d.err
+}
+
+func (d *decoder) decodeStruct(value reflect.Value, indexes []int) (bool, error) {
+	valueType := value.Type()
+	found := false
+
+	d.visiting[valueType] = true
+	defer delete(d.visiting, valueType)
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		var (
+			fieldFound bool
+			err        error
+		)
+		if tagged {
+			fieldFound, err = d.decodeField(tag, value.Field(i), indexes)
+		} else {
+			fieldFound, err = d.decodeNested(value.Field(i), indexes)
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		found = found || fieldFound
+	}
+
+	return found, nil
+}
+
+func (d *decoder) decodeNested(value reflect.Value, indexes []int) (bool, error) {
+	switch {
+	case isNestedStruct(value.Type()):
+		if value.Kind() == reflect.Ptr {
+			if d.visiting[value.Type().Elem()] {
+				return false, nil
+			}
+
+			if !value.CanSet() {
+				if value.IsNil() {
+					return false, nil
+				}
+
+				return d.decodeStruct(value.Elem(), indexes)
+			}
+
+			item := reflect.New(value.Type().Elem())
+			found, err := d.decodeStruct(item.Elem(), indexes)
+			if found && err == nil {
+				value.Set(item)
+			}
+
+			return found, err
+		}
+
+		return d.decodeStruct(value, indexes)
+
+	case isIndexedSlice(value.Type()) && isNestedStruct(value.Type().Elem()):
+		if d.visiting[structType(value.Type().Elem())] {
+			return false, nil
+		}
+
+		items := reflect.MakeSlice(value.Type(), 0, 0)
+		for i := 0; ; i++ {
+			item := reflect.New(value.Type().Elem()).Elem()
+			found, err := d.decodeNested(item, appendIndex(indexes, i))
+			if err != nil {
+				return false, err
+			}
+
+			if !found {
+				break
+			}
+
+			items = reflect.Append(items, item)
+		}
+
+		if items.Len() > 0 {
+			value.Set(items)
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (d *decoder) decodeField(tag string, value reflect.Value, indexes []int) (bool, error) {
+	if isIndexedSlice(value.Type()) {
+		items := reflect.MakeSlice(value.Type(), 0, 0)
+		for i := 0; ; i++ {
+			item := reflect.New(value.Type().Elem()).Elem()
+			found, err := d.decodeField(tag, item, appendIndex(indexes, i))
+			if err != nil {
+				return false, err
+			}
+
+			if !found {
+				break
+			}
+
+			items = reflect.Append(items, item)
+		}
+
+		if items.Len() > 0 {
+			value.Set(items)
+			return true, nil
+		}
+
+		return false, nil
+	}
+
+	key, err := fieldKey(tag, indexes)
+	if err != nil {
+		return false, err
+	}
+
+	encoded, ok := d.values[key]
+	if !ok || len(encoded) == 0 {
+		return false, nil
+	}
+
+	if err := decodeValue(key, encoded[0], value); err != nil {
+		decodeErr, ok := err.(DecodeError)
+		if !ok {
+			return false, err
+		}
+
+		if d.err == nil {
+			d.err = decodeErr
+		}
+	}
+
+	return true, nil
+}
+
+func decodeValue(key string, encoded string, value reflect.Value) error {
+	if value.Kind() == reflect.Ptr {
+		item := reflect.New(value.Type().Elem())
+		if err := decodeValue(key, encoded, item.Elem()); err != nil {
+			return err
+		}
+
+		value.Set(item)
+		return nil
+	}
+
+	if unmarshaler, ok := asUnmarshaler(value); ok {
+		if err := unmarshaler.UnmarshalNVP(encoded); err != nil {
+			return DecodeError{Key: key, Value: encoded, Type: value.Type(), Err: err}
+		}
+
+		return nil
+	}
+
+	if value.Kind() == reflect.String {
+		value.SetString(encoded)
+		return nil
+	}
+
+	if encoded == "" {
+		return nil
+	}
+
+	var err error
+	switch {
+	case value.Type() == timeType:
+		var parsed time.Time
+		if parsed, err = time.Parse(timeFormat, encoded); err == nil {
+			value.Set(reflect.ValueOf(parsed))
+		}
+	case value.Kind() == reflect.Bool:
+		var parsed bool
+		if parsed, err = strconv.ParseBool(encoded); err == nil {
+			value.SetBool(parsed)
+		}
+	case value.Kind() >= reflect.Int && value.Kind() <= reflect.Int64:
+		var parsed int64
+		if parsed, err = strconv.ParseInt(encoded, 10, value.Type().Bits()); err == nil {
+			value.SetInt(parsed)
+		}
+	case value.Kind() >= reflect.Uint && value.Kind() <= reflect.Uint64:
+		var parsed uint64
+		if parsed, err = strconv.ParseUint(encoded, 10, value.Type().Bits()); err == nil {
+			value.SetUint(parsed)
+		}
+	case value.Kind() == reflect.Float32 || value.Kind() == reflect.Float64:
+		var parsed float64
+		if parsed, err = strconv.ParseFloat(encoded, value.Type().Bits()); err == nil {
+			value.SetFloat(parsed)
+		}
+	default:
+		return UnsupportedTypeError{Key: key, Type: value.Type()}
+	}
+
+	if err != nil {
+		return DecodeError{Key: key, Value: encoded, Type: value.Type(), Err: err}
+	}
+
+	return nil
+}
+
+func asUnmarshaler(value reflect.Value) (Unmarshaler, bool) {
+	if value.CanAddr() && reflect.PtrTo(value.Type()).Implements(unmarshalerType) {
+		return value.Addr().Interface().(Unmarshaler), true
+	}
+
+	if value.Type().Implements(unmarshalerType) && value.Kind() != reflect.Struct {
+		return value.Interface().(Unmarshaler), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,189 @@
+package nvp_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+type (
+	Lower string
+
+	DecodeError struct {
+		Code    string `nvp_field:"L_ERRORCODE%d"`
+		Message string `nvp_field:"L_SHORTMESSAGE%d"`
+	}
+
+	DecodeResponse struct {
+		Ack       string    `nvp_field:"ACK"`
+		TimeStamp time.Time `nvp_field:"TIMESTAMP"`
+		Count     int       `nvp_field:"COUNT"`
+		Amount    float64   `nvp_field:"AMT"`
+		Flag      bool      `nvp_field:"FLAG"`
+		Optional  *int      `nvp_field:"OPTIONAL"`
+		Code      Lower     `nvp_field:"CODE"`
+		Amounts   []float64 `nvp_field:"L_AMT%d"`
+		Address   *EncodeAddress
+		Requests  []EncodeRequest
+		Errors    []DecodeError
+	}
+)
+
+type (
+	embeddedInner struct {
+		Name string `nvp_field:"NAME"`
+	}
+
+	EmbeddedPointer struct {
+		*embeddedInner
+		Ack string `nvp_field:"ACK"`
+	}
+
+	RecursiveNode struct {
+		Name     string `nvp_field:"NAME"`
+		Next     *RecursiveNode
+		Children []RecursiveNode
+	}
+)
+
+func (l *Lower) UnmarshalNVP(value string) error {
+	if value == "" {
+		return errors.New("empty value")
+	}
+
+	*l = Lower(strings.ToLower(value))
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("DecodesSupportedTypes", func(t *testing.T) {
+		data, _ := url.ParseQuery(`ACK=Success&TIMESTAMP=2011%2d11%2d15T20%3a27%3a02Z&COUNT=3&AMT=10.50&FLAG=1&CODE=GBP&L_AMT0=1.00&L_AMT1=2.50&L_ERRORCODE0=10002&L_SHORTMESSAGE0=Security+error&L_ERRORCODE1=10001&L_SHORTMESSAGE1=Internal+Error&SHIPTOSTREET=1+Test+Street&PAYMENTREQUEST_0_AMT=10.50&L_PAYMENTREQUEST_0_NAME0=one&L_PAYMENTREQUEST_0_NAME1=two`)
+
+		response := DecodeResponse{}
+		if err := nvp.Unmarshal(data, &response); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if response.Ack != "Success" {
+			t.Fatalf("Expected Ack to be 'Success', got: '%s'", response.Ack)
+		}
+
+		expectedTime := time.Date(2011, 11, 15, 20, 27, 2, 0, time.UTC)
+		if !response.TimeStamp.Equal(expectedTime) {
+			t.Fatalf("Expected TimeStamp to be '%s', got: '%s'", expectedTime, response.TimeStamp)
+		}
+
+		if response.Count != 3 || response.Amount != 10.50 || !response.Flag {
+			t.Fatalf("Expected Count 3, Amount 10.50 and Flag true, got: %d, %.2f, %t", response.Count, response.Amount, response.Flag)
+		}
+
+		if response.Optional != nil {
+			t.Fatalf("Expected Optional to be nil, got: %v", *response.Optional)
+		}
+
+		if response.Code != "gbp" {
+			t.Fatalf("Expected Code to be 'gbp', got: '%s'", response.Code)
+		}
+
+		if len(response.Amounts) != 2 || response.Amounts[1] != 2.50 {
+			t.Fatalf("Expected 2 amounts, got: %v", response.Amounts)
+		}
+
+		if len(response.Errors) != 2 || response.Errors[1].Code != "10001" || response.Errors[1].Message != "Internal Error" {
+			t.Fatalf("Expected 2 errors, got: %v", response.Errors)
+		}
+
+		if response.Address == nil || response.Address.Street != "1 Test Street" {
+			t.Fatalf("Expected Address to be decoded, got: %v", response.Address)
+		}
+
+		if len(response.Requests) != 1 || len(response.Requests[0].Items) != 2 || response.Requests[0].Items[1].Name != "two" {
+			t.Fatalf("Expected 1 request with 2 items, got: %v", response.Requests)
+		}
+	})
+
+	t.Run("LeavesMissingNestedStructsNil", func(t *testing.T) {
+		response := DecodeResponse{}
+		nvp.Unmarshal(url.Values{"ACK": {"Success"}}, &response)
+
+		if response.Address != nil {
+			t.Fatalf("Expected Address to be nil, got: %v", response.Address)
+		}
+	})
+
+	t.Run("ReturnsDecodeErrorForInvalidValue", func(t *testing.T) {
+		response := DecodeResponse{}
+		err := nvp.Unmarshal(url.Values{"TIMESTAMP": {"yesterday"}}, &response)
+
+		var decodeErr nvp.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("Expected DecodeError, got: %v", err)
+		}
+
+		if decodeErr.Key != "TIMESTAMP" {
+			t.Fatalf("Expected Key to be 'TIMESTAMP', got: '%s'", decodeErr.Key)
+		}
+	})
+
+	t.Run("DecodesRemainingFieldsAfterDecodeError", func(t *testing.T) {
+		response := DecodeResponse{}
+		err := nvp.Unmarshal(url.Values{"TIMESTAMP": {"yesterday"}, "COUNT": {"many"}, "ACK": {"Success"}}, &response)
+
+		var decodeErr nvp.DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Fatalf("Expected DecodeError, got: %v", err)
+		}
+
+		if response.Ack != "Success" || !response.TimeStamp.IsZero() || response.Count != 0 {
+			t.Fatalf("Expected only ACK to be decoded, got: %+v", response)
+		}
+	})
+
+	t.Run("ReturnsUnmarshalerError", func(t *testing.T) {
+		response := DecodeResponse{}
+		err := nvp.Unmarshal(url.Values{"CODE": {""}}, &response)
+
+		if err == nil {
+			t.Fatalf("Expected an error, got: %v", err)
+		}
+	})
+
+	t.Run("SkipsUnsetEmbeddedUnexportedPointers", func(t *testing.T) {
+		response := EmbeddedPointer{}
+		err := nvp.Unmarshal(url.Values{"ACK": {"Success"}, "NAME": {"name"}}, &response)
+
+		if err != nil || response.Ack != "Success" || response.embeddedInner != nil {
+			t.Fatalf("Expected ACK to be decoded and embedded pointer skipped, got: %v, %+v", err, response)
+		}
+	})
+
+	t.Run("DecodesIntoSetEmbeddedUnexportedPointers", func(t *testing.T) {
+		response := EmbeddedPointer{embeddedInner: &embeddedInner{}}
+		err := nvp.Unmarshal(url.Values{"NAME": {"name"}}, &response)
+
+		if err != nil || response.Name != "name" {
+			t.Fatalf("Expected NAME to be decoded, got: %v, %+v", err, response)
+		}
+	})
+
+	t.Run("WalksRecursiveTypesOnce", func(t *testing.T) {
+		node := RecursiveNode{}
+		err := nvp.Unmarshal(url.Values{"NAME": {"root"}}, &node)
+
+		if err != nil || node.Name != "root" || node.Next != nil || node.Children != nil {
+			t.Fatalf("Expected only the root to be decoded, got: %v, %+v", err, node)
+		}
+	})
+
+	t.Run("ReturnsErrorForNonPointer", func(t *testing.T) {
+		err := nvp.Unmarshal(url.Values{}, DecodeResponse{})
+
+		if err == nil {
+			t.Fatalf("Expected an error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package nvp
+
+import (
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+type encoder struct {
+	values url.Values
+}
+
+// Marshal encodes a struct, or pointer to a struct, into NVP values.
+//
+// Zero values are omitted, so fields that must be sent as zero should be
+// pointers. Booleans are encoded as "1", floats with two decimal places and
+// times in RFC 3339 format in UTC.
+func Marshal(v interface{}) (url.Values, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil, UnsupportedTypeError{Type: reflect.TypeOf(v)}
+	}
+
+	addressable := reflect.New(value.Type()).Elem()
+	addressable.Set(value)
+
+	e := &encoder{values: url.Values{}}
+	if err := e.encodeStruct(addressable, nil); err != nil {
+		return nil, err
+	}
+
+	return e.values, nil
+}
+
+func (e *encoder) encodeStruct(value reflect.Value, indexes []int) error {
+	valueType := value.Type()
+
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag, tagged := field.Tag.Lookup(tagName)
+		if tag == "-" {
+			continue
+		}
+
+		var err error
+		if tagged {
+			err = e.encodeField(tag, value.Field(i), indexes)
+		} else {
+			err = e.encodeNested(value.Field(i), indexes)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeNested(value reflect.Value, indexes []int) error {
+	switch {
+	case isNestedStruct(value.Type()):
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				return nil
+			}
+			value = value.Elem()
+		}
+
+		return e.encodeStruct(value, indexes)
+
+	case isIndexedSlice(value.Type()) && isNestedStruct(value.Type().Elem()):
+		for i := 0; i < value.Len(); i++ {
+			if err := e.encodeNested(value.Index(i), appendIndex(indexes, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (e *encoder) encodeField(tag string, value reflect.Value, indexes []int) error {
+	if isIndexedSlice(value.Type()) {
+		for i := 0; i < value.Len(); i++ {
+			if err := e.encodeField(tag, value.Index(i), appendIndex(indexes, i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	key, err := fieldKey(tag, indexes)
+	if err != nil {
+		return err
+	}
+
+	encoded, ok, err := encodeValue(key, value, true)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		e.values.Set(key, encoded)
+	}
+
+	return nil
+}
+
+func encodeValue(key string, value reflect.Value, omitZero bool) (string, bool, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", false, nil
+		}
+
+		return encodeValue(key, value.Elem(), false)
+	}
+
+	if omitZero && value.IsZero() {
+		return "", false, nil
+	}
+
+	if marshaler, ok := asMarshaler(value); ok {
+		encoded, err := marshaler.MarshalNVP()
+		if err != nil {
+			return "", false, err
+		}
+
+		return encoded, true, nil
+	}
+
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).UTC().Format(timeFormat), true, nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true, nil
+	case reflect.Bool:
+		if value.Bool() {
+			return "1", true, nil
+		}
+		return "0", true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', 2, 64), true, nil
+	}
+
+	return "", false, UnsupportedTypeError{Key: key, Type: value.Type()}
+}
+
+func asMarshaler(value reflect.Value) (Marshaler, bool) {
+	if value.Type().Implements(marshalerType) {
+		return value.Interface().(Marshaler), true
+	}
+
+	if value.CanAddr() && reflect.PtrTo(value.Type()).Implements(marshalerType) {
+		return value.Addr().Interface().(Marshaler), true
+	}
+
+	return nil, false
+}
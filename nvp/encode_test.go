@@ -0,0 +1,117 @@
+package nvp_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+type (
+	Upper string
+
+	Failing bool
+
+	EncodeItem struct {
+		Name   string  `nvp_field:"L_PAYMENTREQUEST_%d_NAME%d"`
+		Amount float64 `nvp_field:"L_PAYMENTREQUEST_%d_AMT%d"`
+	}
+
+	EncodeRequest struct {
+		Amount float64 `nvp_field:"PAYMENTREQUEST_%d_AMT"`
+		Items  []EncodeItem
+	}
+
+	EncodeAddress struct {
+		Street string `nvp_field:"SHIPTOSTREET"`
+	}
+
+	EncodePayload struct {
+		Method    string    `nvp_field:"METHOD"`
+		Count     int       `nvp_field:"COUNT"`
+		Flag      bool      `nvp_field:"FLAG"`
+		Explicit  *bool     `nvp_field:"EXPLICIT"`
+		Start     time.Time `nvp_field:"STARTDATE"`
+		Code      Upper     `nvp_field:"CODE"`
+		Emails    []string  `nvp_field:"L_EMAIL"`
+		Ignored   string    `nvp_field:"-"`
+		Address   *EncodeAddress
+		Requests  []EncodeRequest
+		unexposed string
+	}
+)
+
+func (u Upper) MarshalNVP() (string, error) {
+	return strings.ToUpper(string(u)), nil
+}
+
+func (f Failing) MarshalNVP() (string, error) {
+	return "", errors.New("cannot marshal")
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("EncodesSupportedTypes", func(t *testing.T) {
+		explicit := false
+		data, err := nvp.Marshal(EncodePayload{
+			Method:    "SetExpressCheckout",
+			Count:     3,
+			Flag:      true,
+			Explicit:  &explicit,
+			Start:     time.Date(2011, 11, 15, 20, 27, 2, 0, time.UTC),
+			Code:      "gbp",
+			Emails:    []string{"a@test.com", "b@test.com"},
+			Ignored:   "ignored",
+			Address:   &EncodeAddress{Street: "1 Test Street"},
+			unexposed: "unexposed",
+			Requests: []EncodeRequest{
+				{Amount: 10.5, Items: []EncodeItem{{Name: "one", Amount: 10.5}}},
+				{Amount: 2, Items: []EncodeItem{{Name: "two", Amount: 1}, {Name: "three", Amount: 1}}},
+			},
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		expected := `CODE=GBP&COUNT=3&EXPLICIT=0&FLAG=1&L_EMAIL0=a%40test.com&L_EMAIL1=b%40test.com&L_PAYMENTREQUEST_0_AMT0=10.50&L_PAYMENTREQUEST_0_NAME0=one&L_PAYMENTREQUEST_1_AMT0=1.00&L_PAYMENTREQUEST_1_AMT1=1.00&L_PAYMENTREQUEST_1_NAME0=two&L_PAYMENTREQUEST_1_NAME1=three&METHOD=SetExpressCheckout&PAYMENTREQUEST_0_AMT=10.50&PAYMENTREQUEST_1_AMT=2.00&SHIPTOSTREET=1+Test+Street&STARTDATE=2011-11-15T20%3A27%3A02Z`
+		if data.Encode() != expected {
+			t.Fatalf("Expected '%s', got '%s'", expected, data.Encode())
+		}
+	})
+
+	t.Run("OmitsZeroValues", func(t *testing.T) {
+		data, _ := nvp.Marshal(&EncodePayload{Method: "GetBalance"})
+
+		if data.Encode() != "METHOD=GetBalance" {
+			t.Fatalf("Expected 'METHOD=GetBalance', got '%s'", data.Encode())
+		}
+	})
+
+	t.Run("ReturnsErrorForNonStruct", func(t *testing.T) {
+		_, err := nvp.Marshal("string")
+
+		if err == nil {
+			t.Fatalf("Expected an error, got: %v", err)
+		}
+	})
+
+	t.Run("ReturnsMarshalerError", func(t *testing.T) {
+		_, err := nvp.Marshal(struct {
+			Value Failing `nvp_field:"VALUE"`
+		}{Value: true})
+
+		if err == nil {
+			t.Fatalf("Expected an error, got: %v", err)
+		}
+	})
+
+	t.Run("ReturnsErrorForMissingIndex", func(t *testing.T) {
+		_, err := nvp.Marshal(EncodeItem{Name: "one"})
+
+		if err == nil {
+			t.Fatalf("Expected an error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+// Package nvp implements encoding and decoding of structs to and from the
+// PayPal name-value pair format.
+//
+// Struct fields are mapped using the "nvp_field" tag. Fields inside a slice
+// are indexed, either by formatting the tag with the index of each
+// enclosing slice (e.g. "L_PAYMENTREQUEST_%d_NAME%d"), or by appending the
+// innermost index when the tag contains no verbs (e.g. "L_EMAIL" becomes
+// "L_EMAIL0", "L_EMAIL1", ...). Untagged struct, pointer to struct and slice
+// of struct fields are walked recursively, and a tag of "-" skips the field.
+package nvp
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tagName    = "nvp_field"
+	indexVerb  = "%d"
+	timeFormat = time.RFC3339
+)
+
+type (
+	// Marshaler interface for types that can encode themselves into a
+	// single NVP value.
+	Marshaler interface {
+		MarshalNVP() (string, error)
+	}
+
+	// Unmarshaler interface for types that can decode themselves from a
+	// single NVP value, which may be empty.
+	Unmarshaler interface {
+		UnmarshalNVP(string) error
+	}
+
+	// UnsupportedTypeError returned when a tagged field has a type that
+	// cannot be encoded or decoded.
+	UnsupportedTypeError struct {
+		Key  string
+		Type reflect.Type
+	}
+
+	// InvalidUnmarshalError returned when Unmarshal is not given a non-nil
+	// pointer to a struct.
+	InvalidUnmarshalError struct {
+		Type reflect.Type
+	}
+
+	// DecodeError returned when a value cannot be decoded into the field
+	// it maps to.
+	DecodeError struct {
+		Key   string
+		Value string
+		Type  reflect.Type
+		Err   error
+	}
+)
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// Error Formatted error string based on properties.
+func (e UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("nvp: unsupported type %s for key '%s'", e.Type, e.Key)
+}
+
+// Error Formatted error string based on properties.
+func (e InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "nvp: Unmarshal(nil)"
+	}
+
+	return fmt.Sprintf("nvp: Unmarshal(non-pointer or non-struct %s)", e.Type)
+}
+
+// Error Formatted error string based on properties.
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("nvp: cannot decode '%s' value '%s' into %s: %s", e.Key, e.Value, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func fieldKey(tag string, indexes []int) (string, error) {
+	verbs := strings.Count(tag, indexVerb)
+	if verbs == 0 {
+		if len(indexes) == 0 {
+			return tag, nil
+		}
+
+		return tag + strconv.Itoa(indexes[len(indexes)-1]), nil
+	}
+
+	if verbs > len(indexes) {
+		return "", fmt.Errorf("nvp: tag '%s' needs %d indexes, only %d available", tag, verbs, len(indexes))
+	}
+
+	args := make([]interface{}, verbs)
+	for i, index := range indexes[len(indexes)-verbs:] {
+		args[i] = index
+	}
+
+	return fmt.Sprintf(tag, args...), nil
+}
+
+func appendIndex(indexes []int, index int) []int {
+	next := make([]int, len(indexes), len(indexes)+1)
+	copy(next, indexes)
+
+	return append(next, index)
+}
+
+func isScalar(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+
+	return t.Implements(marshalerType) ||
+		t.Implements(unmarshalerType) ||
+		reflect.PtrTo(t).Implements(marshalerType) ||
+		reflect.PtrTo(t).Implements(unmarshalerType)
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	t = structType(t)
+	return t.Kind() == reflect.Struct && !isScalar(t)
+}
+
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+
+	return t
+}
+
+func isIndexedSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !isScalar(t)
+}
@@ -0,0 +1,56 @@
+package payload
+
+import (
+	"errors"
+
+	"github.com/vidsy/go-paypalnvp/currency"
+	"github.com/vidsy/go-paypalnvp/money"
+)
+
+// defaultCurrencyCode currency PayPal assumes for payment requests without
+// a currency code.
+const defaultCurrencyCode = currency.USD
+
+// checkAmount adds a problem if the amount is not valid in currencyCode,
+// or is in another currency. Required amounts must be above zero.
+func checkAmount(errs *ValidationErrors, field string, amount money.Amount, currencyCode string, required bool) {
+	if amount.IsNegative() || (required && amount.IsZero()) {
+		errs.add(field, errors.New("Expected an amount above zero"))
+		return
+	}
+
+	if amount == (money.Amount{}) {
+		return
+	}
+
+	if amount.Currency() != "" && amount.Currency() != currencyCode {
+		errs.addf(field, "Expected amount %s in %s, got %s", amount, currencyCode, amount.Currency())
+		return
+	}
+
+	if err := amount.WithCurrency(currencyCode).Validate(); err != nil {
+		errs.add(field, err)
+	}
+}
+
+// inCurrency returns the amount in currencyCode, so it's formatted with the
+// decimal places PayPal expects. Unset amounts are left as they are so
+// they're omitted.
+func inCurrency(amount money.Amount, currencyCode string) money.Amount {
+	if amount == (money.Amount{}) {
+		return amount
+	}
+
+	return amount.WithCurrency(currencyCode)
+}
+
+// checkCurrency adds a problem if PayPal does not support currencyCode,
+// returning whether it does.
+func checkCurrency(errs *ValidationErrors, field string, currencyCode string) bool {
+	if err := currency.Validate(currencyCode); err != nil {
+		errs.add(field, err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,291 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+const (
+	// PaymentActionSale sets payment action to a final sale.
+	PaymentActionSale = "Sale"
+
+	// PaymentActionAuthorization sets payment action to an authorization
+	// to be captured later.
+	PaymentActionAuthorization = "Authorization"
+
+	// PaymentActionOrder sets payment action to an order to be authorized
+	// and captured later.
+	PaymentActionOrder = "Order"
+)
+
+type (
+	// SetExpressCheckout payload for starting an express checkout.
+	SetExpressCheckout struct {
+		Method          string `nvp_field:"METHOD"`
+		Token           string `nvp_field:"TOKEN"`
+		ReturnURL       string `nvp_field:"RETURNURL"`
+		CancelURL       string `nvp_field:"CANCELURL"`
+		NoShipping      int    `nvp_field:"NOSHIPPING"`
+		AddressOverride bool   `nvp_field:"ADDROVERRIDE"`
+		Email           string `nvp_field:"EMAIL"`
+		BrandName       string `nvp_field:"BRANDNAME"`
+		LocaleCode      string `nvp_field:"LOCALECODE"`
+		LandingPage     string `nvp_field:"LANDINGPAGE"`
+		SolutionType    string `nvp_field:"SOLUTIONTYPE"`
+		PaymentRequests []PaymentRequest
+	}
+
+	// GetExpressCheckoutDetails payload for fetching the details of an
+	// express checkout after the buyer has returned.
+	GetExpressCheckoutDetails struct {
+		Method string `nvp_field:"METHOD"`
+		Token  string `nvp_field:"TOKEN"`
+	}
+
+	// DoExpressCheckoutPayment payload for completing an express checkout.
+	DoExpressCheckoutPayment struct {
+		Method          string `nvp_field:"METHOD"`
+		Token           string `nvp_field:"TOKEN"`
+		PayerID         string `nvp_field:"PAYERID"`
+		PaymentRequests []PaymentRequest
+	}
+
+	// PaymentRequest contains data about an individual payment within an
+	// express checkout.
+	PaymentRequest struct {
+		Amount         money.Amount `nvp_field:"PAYMENTREQUEST_%d_AMT"`
+		CurrencyCode   string       `nvp_field:"PAYMENTREQUEST_%d_CURRENCYCODE"`
+		ItemAmount     money.Amount `nvp_field:"PAYMENTREQUEST_%d_ITEMAMT"`
+		ShippingAmount money.Amount `nvp_field:"PAYMENTREQUEST_%d_SHIPPINGAMT"`
+		TaxAmount      money.Amount `nvp_field:"PAYMENTREQUEST_%d_TAXAMT"`
+		Description    string       `nvp_field:"PAYMENTREQUEST_%d_DESC"`
+		Custom         string       `nvp_field:"PAYMENTREQUEST_%d_CUSTOM"`
+		InvoiceNumber  string       `nvp_field:"PAYMENTREQUEST_%d_INVNUM"`
+		NotifyURL      string       `nvp_field:"PAYMENTREQUEST_%d_NOTIFYURL"`
+		PaymentAction  string       `nvp_field:"PAYMENTREQUEST_%d_PAYMENTACTION"`
+		ID             string       `nvp_field:"PAYMENTREQUEST_%d_PAYMENTREQUESTID"`
+		SellerID       string       `nvp_field:"PAYMENTREQUEST_%d_SELLERPAYPALACCOUNTID"`
+		TransactionID  string       `nvp_field:"PAYMENTREQUEST_%d_TRANSACTIONID"`
+		ShipTo         *ShippingAddress
+		Items          []PaymentRequestItem
+	}
+
+	// PaymentRequestItem contains data about an individual line item
+	// within a payment request.
+	PaymentRequestItem struct {
+		Name        string       `nvp_field:"L_PAYMENTREQUEST_%d_NAME%d"`
+		Description string       `nvp_field:"L_PAYMENTREQUEST_%d_DESC%d"`
+		Amount      money.Amount `nvp_field:"L_PAYMENTREQUEST_%d_AMT%d"`
+		Number      string       `nvp_field:"L_PAYMENTREQUEST_%d_NUMBER%d"`
+		Quantity    int          `nvp_field:"L_PAYMENTREQUEST_%d_QTY%d"`
+		TaxAmount   money.Amount `nvp_field:"L_PAYMENTREQUEST_%d_TAXAMT%d"`
+		Category    string       `nvp_field:"L_PAYMENTREQUEST_%d_ITEMCATEGORY%d"`
+	}
+
+	// ShippingAddress contains the shipping address of a payment request.
+	ShippingAddress struct {
+		Name        string `nvp_field:"PAYMENTREQUEST_%d_SHIPTONAME"`
+		Street      string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOSTREET"`
+		Street2     string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOSTREET2"`
+		City        string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOCITY"`
+		State       string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOSTATE"`
+		Zip         string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOZIP"`
+		CountryCode string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOCOUNTRYCODE"`
+		Phone       string `nvp_field:"PAYMENTREQUEST_%d_SHIPTOPHONENUM"`
+		Status      string `nvp_field:"PAYMENTREQUEST_%d_ADDRESSSTATUS"`
+	}
+)
+
+// NewSetExpressCheckout creates a new SetExpressCheckout struct with
+// defaults.
+func NewSetExpressCheckout(returnURL string, cancelURL string) *SetExpressCheckout {
+	return &SetExpressCheckout{
+		Method:    "SetExpressCheckout",
+		ReturnURL: returnURL,
+		CancelURL: cancelURL,
+	}
+}
+
+// AddPaymentRequest adds a payment request to the payment requests array.
+func (sec *SetExpressCheckout) AddPaymentRequest(paymentRequest PaymentRequest) {
+	sec.PaymentRequests = append(sec.PaymentRequests, paymentRequest)
+}
+
+// Validate returns ValidationErrors listing every problem with the express
+// checkout and its payment requests, or nil if it's valid.
+func (sec SetExpressCheckout) Validate() error {
+	errs := ValidationErrors{}
+
+	if sec.ReturnURL == "" {
+		errs.add("RETURNURL", errRequired)
+	}
+
+	if sec.CancelURL == "" {
+		errs.add("CANCELURL", errRequired)
+	}
+	validatePaymentRequests(&errs, sec.PaymentRequests)
+
+	return errs.err()
+}
+
+// Serialize convert struct into NVP key=value format for the express
+// checkout, returning ValidationErrors if it's invalid.
+func (sec SetExpressCheckout) Serialize() (url.Values, error) {
+	if err := sec.Validate(); err != nil {
+		return nil, err
+	}
+	sec.PaymentRequests = paymentRequestsInCurrency(sec.PaymentRequests)
+
+	return nvp.Marshal(sec)
+}
+
+// NewGetExpressCheckoutDetails creates a new GetExpressCheckoutDetails
+// struct for the given token.
+func NewGetExpressCheckoutDetails(token string) *GetExpressCheckoutDetails {
+	return &GetExpressCheckoutDetails{
+		Method: "GetExpressCheckoutDetails",
+		Token:  token,
+	}
+}
+
+// Validate returns ValidationErrors if the token is missing.
+func (gecd GetExpressCheckoutDetails) Validate() error {
+	errs := ValidationErrors{}
+
+	if gecd.Token == "" {
+		errs.add("TOKEN", errRequired)
+	}
+
+	return errs.err()
+}
+
+// Serialize convert struct into NVP key=value format for the express
+// checkout details, returning ValidationErrors if it's invalid.
+func (gecd GetExpressCheckoutDetails) Serialize() (url.Values, error) {
+	if err := gecd.Validate(); err != nil {
+		return nil, err
+	}
+
+	return nvp.Marshal(gecd)
+}
+
+// NewDoExpressCheckoutPayment creates a new DoExpressCheckoutPayment struct
+// for the given token and payer.
+func NewDoExpressCheckoutPayment(token string, payerID string) *DoExpressCheckoutPayment {
+	return &DoExpressCheckoutPayment{
+		Method:  "DoExpressCheckoutPayment",
+		Token:   token,
+		PayerID: payerID,
+	}
+}
+
+// AddPaymentRequest adds a payment request to the payment requests array.
+func (decp *DoExpressCheckoutPayment) AddPaymentRequest(paymentRequest PaymentRequest) {
+	decp.PaymentRequests = append(decp.PaymentRequests, paymentRequest)
+}
+
+// Validate returns ValidationErrors listing every problem with the express
+// checkout payment and its payment requests, or nil if it's valid.
+func (decp DoExpressCheckoutPayment) Validate() error {
+	errs := ValidationErrors{}
+
+	if decp.Token == "" {
+		errs.add("TOKEN", errRequired)
+	}
+
+	if decp.PayerID == "" {
+		errs.add("PAYERID", errRequired)
+	}
+	validatePaymentRequests(&errs, decp.PaymentRequests)
+
+	return errs.err()
+}
+
+// Serialize convert struct into NVP key=value format for the express
+// checkout payment, returning ValidationErrors if it's invalid.
+func (decp DoExpressCheckoutPayment) Serialize() (url.Values, error) {
+	if err := decp.Validate(); err != nil {
+		return nil, err
+	}
+	decp.PaymentRequests = paymentRequestsInCurrency(decp.PaymentRequests)
+
+	return nvp.Marshal(decp)
+}
+
+// AddItem adds an item to the payment request items array.
+func (pr *PaymentRequest) AddItem(item PaymentRequestItem) {
+	pr.Items = append(pr.Items, item)
+}
+
+// currencyCode currency of the payment request, USD if it has none.
+func (pr PaymentRequest) currencyCode() string {
+	if pr.CurrencyCode == "" {
+		return defaultCurrencyCode
+	}
+
+	return pr.CurrencyCode
+}
+
+func (pr PaymentRequest) validate(errs *ValidationErrors, index int) {
+	field := func(format string, indexes ...interface{}) string {
+		return fmt.Sprintf(format, append([]interface{}{index}, indexes...)...)
+	}
+
+	currencyCode := pr.currencyCode()
+	if !checkCurrency(errs, field("PAYMENTREQUEST_%d_CURRENCYCODE"), currencyCode) {
+		return
+	}
+
+	checkAmount(errs, field("PAYMENTREQUEST_%d_AMT"), pr.Amount, currencyCode, true)
+	checkAmount(errs, field("PAYMENTREQUEST_%d_ITEMAMT"), pr.ItemAmount, currencyCode, false)
+	checkAmount(errs, field("PAYMENTREQUEST_%d_SHIPPINGAMT"), pr.ShippingAmount, currencyCode, false)
+	checkAmount(errs, field("PAYMENTREQUEST_%d_TAXAMT"), pr.TaxAmount, currencyCode, false)
+
+	for i, item := range pr.Items {
+		checkAmount(errs, field("L_PAYMENTREQUEST_%d_AMT%d", i), item.Amount, currencyCode, false)
+		checkAmount(errs, field("L_PAYMENTREQUEST_%d_TAXAMT%d", i), item.TaxAmount, currencyCode, false)
+	}
+}
+
+// InCurrency returns a copy of the payment request with its amounts and
+// those of its items in its currency, USD if it has none.
+func (pr PaymentRequest) InCurrency() PaymentRequest {
+	currencyCode := pr.currencyCode()
+	pr.Amount = inCurrency(pr.Amount, currencyCode)
+	pr.ItemAmount = inCurrency(pr.ItemAmount, currencyCode)
+	pr.ShippingAmount = inCurrency(pr.ShippingAmount, currencyCode)
+	pr.TaxAmount = inCurrency(pr.TaxAmount, currencyCode)
+
+	items := make([]PaymentRequestItem, len(pr.Items))
+	for i, item := range pr.Items {
+		item.Amount = inCurrency(item.Amount, currencyCode)
+		item.TaxAmount = inCurrency(item.TaxAmount, currencyCode)
+		items[i] = item
+	}
+	pr.Items = items
+
+	return pr
+}
+
+func validatePaymentRequests(errs *ValidationErrors, paymentRequests []PaymentRequest) {
+	if len(paymentRequests) == 0 {
+		errs.add("PAYMENTREQUEST_0_AMT", errors.New("Expected at least one payment request"))
+	}
+
+	for i, paymentRequest := range paymentRequests {
+		paymentRequest.validate(errs, i)
+	}
+}
+
+func paymentRequestsInCurrency(paymentRequests []PaymentRequest) []PaymentRequest {
+	converted := make([]PaymentRequest, len(paymentRequests))
+	for i, paymentRequest := range paymentRequests {
+		converted[i] = paymentRequest.InCurrency()
+	}
+
+	return converted
+}
@@ -0,0 +1,104 @@
+package payload_test
+
+import (
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+func TestSetExpressCheckout(t *testing.T) {
+	t.Run(".Serialize()", func(t *testing.T) {
+		t.Run("ReturnsErrorWhenNoPaymentRequests", func(t *testing.T) {
+			setExpressCheckout := payload.NewSetExpressCheckout("https://test.com/return", "https://test.com/cancel")
+			_, err := setExpressCheckout.Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForUnsupportedCurrency", func(t *testing.T) {
+			setExpressCheckout := payload.NewSetExpressCheckout("https://test.com/return", "https://test.com/cancel")
+			setExpressCheckout.AddPaymentRequest(payload.PaymentRequest{Amount: money.MustParse("15.00", ""), CurrencyCode: "XYZ"})
+			_, err := setExpressCheckout.Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsCorrectlySerializedPayload", func(t *testing.T) {
+			setExpressCheckout := payload.NewSetExpressCheckout("https://test.com/return", "https://test.com/cancel")
+			paymentRequest := payload.PaymentRequest{
+				Amount:        money.MustParse("15.00", "GBP"),
+				CurrencyCode:  "GBP",
+				PaymentAction: payload.PaymentActionSale,
+			}
+			paymentRequest.AddItem(payload.PaymentRequestItem{Name: "Video", Amount: money.MustParse("10.00", "GBP"), Quantity: 1})
+			paymentRequest.AddItem(payload.PaymentRequestItem{Name: "Edit", Amount: money.MustParse("5.00", "GBP"), Quantity: 1})
+
+			setExpressCheckout.AddPaymentRequest(paymentRequest)
+
+			expectedPayload := `CANCELURL=https%3A%2F%2Ftest.com%2Fcancel&L_PAYMENTREQUEST_0_AMT0=10.00&L_PAYMENTREQUEST_0_AMT1=5.00&L_PAYMENTREQUEST_0_NAME0=Video&L_PAYMENTREQUEST_0_NAME1=Edit&L_PAYMENTREQUEST_0_QTY0=1&L_PAYMENTREQUEST_0_QTY1=1&METHOD=SetExpressCheckout&PAYMENTREQUEST_0_AMT=15.00&PAYMENTREQUEST_0_CURRENCYCODE=GBP&PAYMENTREQUEST_0_PAYMENTACTION=Sale&RETURNURL=https%3A%2F%2Ftest.com%2Freturn`
+			data, _ := setExpressCheckout.Serialize()
+
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
+			}
+		})
+	})
+}
+
+func TestGetExpressCheckoutDetails(t *testing.T) {
+	t.Run(".Serialize()", func(t *testing.T) {
+		t.Run("ReturnsErrorWhenNoToken", func(t *testing.T) {
+			_, err := payload.NewGetExpressCheckoutDetails("").Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsCorrectlySerializedPayload", func(t *testing.T) {
+			getExpressCheckoutDetails := payload.NewGetExpressCheckoutDetails("EC-123")
+
+			expectedPayload := `METHOD=GetExpressCheckoutDetails&TOKEN=EC-123`
+			data, _ := getExpressCheckoutDetails.Serialize()
+
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
+			}
+		})
+	})
+}
+
+func TestDoExpressCheckoutPayment(t *testing.T) {
+	t.Run(".Serialize()", func(t *testing.T) {
+		t.Run("ReturnsErrorWhenNoPayerID", func(t *testing.T) {
+			doExpressCheckoutPayment := payload.NewDoExpressCheckoutPayment("EC-123", "")
+			doExpressCheckoutPayment.AddPaymentRequest(payload.PaymentRequest{Amount: money.MustParse("15.00", "GBP")})
+			_, err := doExpressCheckoutPayment.Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsCorrectlySerializedPayload", func(t *testing.T) {
+			doExpressCheckoutPayment := payload.NewDoExpressCheckoutPayment("EC-123", "PAYER1")
+			doExpressCheckoutPayment.AddPaymentRequest(payload.PaymentRequest{
+				Amount:        money.MustParse("15.00", "GBP"),
+				CurrencyCode:  "GBP",
+				PaymentAction: payload.PaymentActionSale,
+			})
+
+			expectedPayload := `METHOD=DoExpressCheckoutPayment&PAYERID=PAYER1&PAYMENTREQUEST_0_AMT=15.00&PAYMENTREQUEST_0_CURRENCYCODE=GBP&PAYMENTREQUEST_0_PAYMENTACTION=Sale&TOKEN=EC-123`
+			data, _ := doExpressCheckoutPayment.Serialize()
+
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
+			}
+		})
+	})
+}
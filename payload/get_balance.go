@@ -0,0 +1,36 @@
+package payload
+
+import (
+	"net/url"
+
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+type (
+	// GetBalance payload for fetching the account balance.
+	GetBalance struct {
+		Method              string `nvp_field:"METHOD"`
+		ReturnAllCurrencies bool   `nvp_field:"RETURNALLCURRENCIES"`
+	}
+)
+
+// NewGetBalance creates a new GetBalance struct, returning the balance of
+// every currency held when allCurrencies is true and only the primary
+// currency otherwise.
+func NewGetBalance(allCurrencies bool) *GetBalance {
+	return &GetBalance{
+		Method:              "GetBalance",
+		ReturnAllCurrencies: allCurrencies,
+	}
+}
+
+// Validate always returns nil, every GetBalance is valid.
+func (gb GetBalance) Validate() error {
+	return nil
+}
+
+// Serialize convert struct into NVP key=value format for the balance
+// request.
+func (gb GetBalance) Serialize() (url.Values, error) {
+	return nvp.Marshal(gb)
+}
@@ -0,0 +1,22 @@
+package payload_test
+
+import (
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+func TestGetBalance(t *testing.T) {
+	t.Run(".Serialize()", func(t *testing.T) {
+		t.Run("ReturnsCorrectlySerializedPayload", func(t *testing.T) {
+			getBalance := payload.NewGetBalance(true)
+
+			expectedPayload := `METHOD=GetBalance&RETURNALLCURRENCIES=1`
+			data, _ := getBalance.Serialize()
+
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
+			}
+		})
+	})
+}
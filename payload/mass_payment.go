@@ -4,8 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"reflect"
-	"strconv"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
 )
 
 const (
@@ -17,15 +18,21 @@ const (
 
 	// ReceiverTypeUserID sets receiver type to user id.
 	ReceiverTypeUserID = "UserID"
+
+	// MaxMassPaymentItems maximum number of items PayPal accepts in a
+	// single mass payment, larger payments must be split.
+	MaxMassPaymentItems = 250
 )
 
+var receiverFields = map[string]string{
+	ReceiverTypeEmail:  "L_EMAIL",
+	ReceiverTypePhone:  "L_RECEIVERPHONE",
+	ReceiverTypeUserID: "L_RECEIVERID",
+}
+
 type (
 	// MassPayment payload for mass payment request.
 	MassPayment struct {
-		User         string `nvp_field:"USER"`
-		Password     string `nvp_field:"PWD"`
-		Signature    string `nvp_field:"SIGNATURE"`
-		Version      string `nvp_field:"VERSION"`
 		Method       string `nvp_field:"METHOD"`
 		EmailSubject string `nvp_field:"EMAILSUBJECT"`
 		CurrencyCode string `nvp_field:"CURRENCYCODE"`
@@ -36,12 +43,12 @@ type (
 	// MassPaymentItem contains data about an individual mass payment
 	// item.
 	MassPaymentItem struct {
-		Email  string  `nvp_field:"L_EMAIL"`
-		Phone  string  `nvp_field:"L_RECEIVERPHONE"`
-		UserID string  `nvp_field:"L_RECEIVERID"`
-		Amount float64 `nvp_field:"L_AMT"`
-		ID     string  `nvp_field:"L_UNIQUEID"`
-		Note   string  `nvp_field:"L_NOTE"`
+		Email  string       `nvp_field:"L_EMAIL"`
+		Phone  string       `nvp_field:"L_RECEIVERPHONE"`
+		UserID string       `nvp_field:"L_RECEIVERID"`
+		Amount money.Amount `nvp_field:"L_AMT"`
+		ID     string       `nvp_field:"L_UNIQUEID"`
+		Note   string       `nvp_field:"L_NOTE"`
 	}
 )
 
@@ -54,76 +61,129 @@ func NewMassPayment(currency string, receiverType string) *MassPayment {
 	}
 }
 
-// Credentials sets credentials and API version.
-func (mp *MassPayment) SetCredentials(user string, password string, signature string, apiVersion string) {
-	mp.User = user
-	mp.Password = password
-	mp.Signature = signature
-	mp.Version = apiVersion
-}
-
 // AddItem adds an item to the mass payment items array.
 func (mp *MassPayment) AddItem(item MassPaymentItem) {
 	mp.Items = append(mp.Items, item)
 }
 
-// Total gives the total of all payments in the mass payment.
-func (mp MassPayment) Total() float64 {
-	total := 0.0
+// Total gives the total of all payments in the mass payment, in its
+// currency.
+func (mp MassPayment) Total() money.Amount {
+	total := money.New(0, mp.CurrencyCode)
 	for _, item := range mp.Items {
-		total += item.Amount
+		total = total.Add(item.Amount)
 	}
 
 	return total
 }
 
-// Serialize convert struct into NVP key=value format for the masspayment.
-func (mp MassPayment) Serialize() (string, error) {
-	data := url.Values{}
-	massPaymentType := reflect.TypeOf(mp)
-	massPaymentValue := reflect.ValueOf(mp)
-
-	for i := 0; i < massPaymentType.NumField(); i++ {
-		field := massPaymentType.Field(i)
-		if fieldTag, ok := field.Tag.Lookup("nvp_field"); ok {
-			valueContents := massPaymentValue.Field(i).String()
-			if valueContents != "" {
-				data.Set(fieldTag, massPaymentValue.Field(i).String())
-			}
-		}
+// Split splits the mass payment into mass payments of at most size items,
+// in order, each with the same subject, currency and receiver type. A size
+// outside 1 to MaxMassPaymentItems splits into batches of
+// MaxMassPaymentItems.
+func (mp MassPayment) Split(size int) []*MassPayment {
+	if size <= 0 || size > MaxMassPaymentItems {
+		size = MaxMassPaymentItems
 	}
 
-	for i, item := range mp.Items {
-		item.Serialize(&data, i)
-	}
+	batches := make([]*MassPayment, 0, (len(mp.Items)+size-1)/size)
+	for start := 0; start < len(mp.Items); start += size {
+		end := start + size
+		if end > len(mp.Items) {
+			end = len(mp.Items)
+		}
 
-	if len(mp.Items) == 0 {
-		return "", errors.New("Expected at least one mass payment item")
+		batch := mp
+		batch.Items = append([]MassPaymentItem(nil), mp.Items[start:end]...)
+		batches = append(batches, &batch)
 	}
 
-	return data.Encode(), nil
+	return batches
+}
+
+// Validate returns ValidationErrors listing every problem with the mass
+// payment and its items, or nil if it's valid.
+func (mp MassPayment) Validate() error {
+	return mp.validate(MaxMassPaymentItems)
+}
+
+// ValidateSplit checks the mass payment as Validate does, except for the
+// MaxMassPaymentItems limit, before it is divided into batches with Split.
+func (mp MassPayment) ValidateSplit() error {
+	return mp.validate(0)
 }
 
-// Serialize convert mass payment item into key=value pair and add to existing
-// Values struct.
-func (mpi MassPaymentItem) Serialize(data *url.Values, index int) {
-	itemType := reflect.TypeOf(mpi)
-	itemValue := reflect.ValueOf(mpi)
-
-	for i := 0; i < itemType.NumField(); i++ {
-		field := itemType.Field(i)
-		if fieldTag, ok := field.Tag.Lookup("nvp_field"); ok {
-			value := itemValue.Field(i)
-
-			switch field.Type.Kind() {
-			case reflect.String:
-				valueContents := value.String()
-				if valueContents != "" {
-					data.Set(fmt.Sprintf("%s%d", fieldTag, index), value.String())
+func (mp MassPayment) validate(maxItems int) error {
+	errs := ValidationErrors{}
+
+	validCurrency := checkCurrency(&errs, "CURRENCYCODE", mp.CurrencyCode)
+	receiverField, validReceiverType := receiverFields[mp.ReceiverType]
+	if !validReceiverType {
+		errs.addf("RECEIVERTYPE", "Expected EmailAddress, PhoneNumber or UserID, got '%s'", mp.ReceiverType)
+	}
+	checkMaxLength(&errs, "EMAILSUBJECT", mp.EmailSubject, maxEmailSubjectLength)
+
+	switch {
+	case len(mp.Items) == 0:
+		errs.add("L_EMAIL0", errors.New("Expected at least one mass payment item"))
+	case maxItems > 0 && len(mp.Items) > maxItems:
+		errs.addf(
+			fmt.Sprintf("L_EMAIL%d", maxItems),
+			"Expected at most %d mass payment items, got %d",
+			maxItems,
+			len(mp.Items),
+		)
+	}
+
+	for i, item := range mp.Items {
+		receivers := []struct {
+			field string
+			value string
+		}{
+			{"L_EMAIL", item.Email},
+			{"L_RECEIVERPHONE", item.Phone},
+			{"L_RECEIVERID", item.UserID},
+		}
+		for _, receiver := range receivers {
+			field := indexed(receiver.field, i)
+			switch {
+			case !validReceiverType:
+			case receiver.field != receiverField:
+				if receiver.value != "" {
+					errs.addf(field, "Expected no value for receiver type %s", mp.ReceiverType)
 				}
-			case reflect.Float64:
-				data.Set(fmt.Sprintf("%s%d", fieldTag, index), strconv.FormatFloat(value.Float(), 'f', 2, 64))
+			case receiver.value == "":
+				errs.add(field, errRequired)
+			case mp.ReceiverType == ReceiverTypeEmail && !validEmail(receiver.value):
+				errs.addf(field, "Invalid email address '%s'", receiver.value)
+			case mp.ReceiverType == ReceiverTypePhone && !validPhone(receiver.value):
+				errs.addf(field, "Invalid phone number '%s'", receiver.value)
 			}
 		}
+
+		if validCurrency {
+			checkAmount(&errs, indexed("L_AMT", i), item.Amount, mp.CurrencyCode, true)
+		}
+		checkMaxLength(&errs, indexed("L_UNIQUEID", i), item.ID, maxUniqueIDLength)
+		checkMaxLength(&errs, indexed("L_NOTE", i), item.Note, maxNoteLength)
 	}
+
+	return errs.err()
+}
+
+// Serialize convert struct into NVP key=value format for the masspayment,
+// returning ValidationErrors if it's invalid.
+func (mp MassPayment) Serialize() (url.Values, error) {
+	if err := mp.Validate(); err != nil {
+		return nil, err
+	}
+
+	items := make([]MassPaymentItem, len(mp.Items))
+	for i, item := range mp.Items {
+		item.Amount = inCurrency(item.Amount, mp.CurrencyCode)
+		items[i] = item
+	}
+	mp.Items = items
+
+	return nvp.Marshal(mp)
 }
@@ -1,8 +1,13 @@
 package payload_test
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/vidsy/go-paypalnvp/currency"
+	"github.com/vidsy/go-paypalnvp/money"
 	"github.com/vidsy/go-paypalnvp/payload"
 )
 
@@ -22,22 +27,132 @@ func TestMassPayment(t *testing.T) {
 	t.Run(".Total()", func(t *testing.T) {
 		massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
 		itemOne := payload.MassPaymentItem{
-			Amount: 10.50,
+			Amount: money.MustParse("10.50", "GBP"),
 		}
 		itemTwo := payload.MassPaymentItem{
-			Amount: 13.40,
+			Amount: money.MustParse("13.40", "GBP"),
 		}
 
 		massPayment.AddItem(itemOne)
 		massPayment.AddItem(itemTwo)
 
-		expectedTotal := 23.90
+		expectedTotal := money.MustParse("23.90", "GBP")
 		if massPayment.Total() != expectedTotal {
-			t.Fatalf("Expected .Total() to be %.2f, got '%.2f'", expectedTotal, massPayment.Total())
+			t.Fatalf("Expected .Total() to be %s, got '%s'", expectedTotal, massPayment.Total())
 		}
 
 	})
 
+	t.Run(".Split()", func(t *testing.T) {
+		massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+		massPayment.EmailSubject = "Test email"
+		for i := 0; i < 5; i++ {
+			massPayment.AddItem(payload.MassPaymentItem{Amount: money.New(int64(i+1), "GBP")})
+		}
+
+		t.Run("SplitsItemsInOrder", func(t *testing.T) {
+			batches := massPayment.Split(2)
+
+			if len(batches) != 3 || len(batches[0].Items) != 2 || len(batches[2].Items) != 1 {
+				t.Fatalf("Expected batches of 2, 2 and 1 items, got: %d batches", len(batches))
+			}
+
+			if batches[1].Items[0].Amount != massPayment.Items[2].Amount || batches[2].EmailSubject != "Test email" {
+				t.Fatalf("Expected batches to keep item order and subject, got: %+v", batches[1])
+			}
+		})
+
+		t.Run("UsesMaximumForInvalidSize", func(t *testing.T) {
+			if batches := massPayment.Split(0); len(batches) != 1 || len(batches[0].Items) != 5 {
+				t.Fatalf("Expected a single batch, got: %d", len(batches))
+			}
+		})
+	})
+
+	t.Run(".Validate()", func(t *testing.T) {
+		t.Run("ReturnsNilForValidPayment", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypePhone)
+			massPayment.AddItem(payload.MassPaymentItem{Phone: "+44 7700 900123", Amount: money.New(100, "GBP")})
+
+			if err := massPayment.Validate(); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+
+		t.Run("ListsEveryProblem", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+			massPayment.EmailSubject = strings.Repeat("a", 256)
+			massPayment.AddItem(payload.MassPaymentItem{
+				Email:  "not-an-email",
+				Phone:  "+44 7700 900123",
+				Amount: money.New(100, "GBP"),
+				ID:     strings.Repeat("1", 31),
+			})
+			massPayment.AddItem(payload.MassPaymentItem{
+				Note: strings.Repeat("a", 4001),
+			})
+
+			err := massPayment.Validate()
+			validationErrors, ok := err.(payload.ValidationErrors)
+			if !ok {
+				t.Fatalf("Expected ValidationErrors, got: %v", err)
+			}
+
+			expectedFields := []string{
+				"EMAILSUBJECT",
+				"L_EMAIL0",
+				"L_RECEIVERPHONE0",
+				"L_UNIQUEID0",
+				"L_EMAIL1",
+				"L_AMT1",
+				"L_NOTE1",
+			}
+			if !reflect.DeepEqual(validationErrors.Fields(), expectedFields) {
+				t.Fatalf("Expected fields %v, got: %v", expectedFields, validationErrors.Fields())
+			}
+		})
+
+		t.Run("ReturnsErrorForUnknownReceiverType", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", "Carrier pigeon")
+			massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.New(100, "GBP")})
+
+			err := massPayment.Validate()
+			if validationErrors, ok := err.(payload.ValidationErrors); !ok || validationErrors.Fields()[0] != "RECEIVERTYPE" {
+				t.Fatalf("Expected RECEIVERTYPE error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForInvalidPhone", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypePhone)
+			massPayment.AddItem(payload.MassPaymentItem{Phone: "call me", Amount: money.New(100, "GBP")})
+
+			if err := massPayment.Validate(); err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+	})
+
+	t.Run(".ValidateSplit()", func(t *testing.T) {
+		t.Run("AllowsItemsAboveLimit", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+			for i := 0; i <= payload.MaxMassPaymentItems; i++ {
+				massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.New(100, "GBP")})
+			}
+
+			if err := massPayment.ValidateSplit(); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorWithoutItems", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+
+			if err := massPayment.ValidateSplit(); err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+	})
+
 	t.Run(".Serialize()", func(t *testing.T) {
 		t.Run("ReturnsErrorWhenNoDataSet", func(t *testing.T) {
 			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
@@ -48,31 +163,88 @@ func TestMassPayment(t *testing.T) {
 			}
 		})
 
+		t.Run("ReturnsErrorAboveItemLimit", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+			for i := 0; i <= payload.MaxMassPaymentItems; i++ {
+				massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.New(100, "GBP")})
+			}
+
+			if _, err := massPayment.Serialize(); err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForUnsupportedCurrency", func(t *testing.T) {
+			massPayment := payload.NewMassPayment("XYZ", payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.MustParse("1.00", "")})
+			_, err := massPayment.Serialize()
+
+			if !errors.As(err, &currency.UnsupportedError{}) {
+				t.Fatalf("Expected UnsupportedError, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForDecimalsInZeroDecimalCurrency", func(t *testing.T) {
+			massPayment := payload.NewMassPayment(currency.JPY, payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.MustParse("100.50", "")})
+
+			if _, err := massPayment.Serialize(); err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorForItemInOtherCurrency", func(t *testing.T) {
+			massPayment := payload.NewMassPayment(currency.GBP, payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.MustParse("1.00", currency.USD)})
+
+			if _, err := massPayment.Serialize(); err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorAboveTransactionMaximum", func(t *testing.T) {
+			massPayment := payload.NewMassPayment(currency.GBP, payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.MustParse("5500.01", currency.GBP)})
+
+			if _, err := massPayment.Serialize(); err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("FormatsAmountsWithCurrencyDecimals", func(t *testing.T) {
+			massPayment := payload.NewMassPayment(currency.JPY, payload.ReceiverTypeEmail)
+			massPayment.AddItem(payload.MassPaymentItem{Email: "test@test.com", Amount: money.MustParse("1000", "")})
+			data, _ := massPayment.Serialize()
+
+			if data.Get("L_AMT0") != "1000" {
+				t.Fatalf("Expected L_AMT0 to be '1000', got: '%s'", data.Get("L_AMT0"))
+			}
+		})
+
 		t.Run("ReturnsCorrectlySerializedPayload", func(t *testing.T) {
 			massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
 			massPayment.EmailSubject = "Test email"
 			itemOne := payload.MassPaymentItem{
 				Email:  "test@test.com",
-				Amount: 1.50,
+				Amount: money.MustParse("1.50", "GBP"),
 				ID:     "123456789",
 				Note:   "A test transaction",
 			}
 			itemTwo := payload.MassPaymentItem{
 				Email:  "test@testtwo.com",
-				Amount: 1.60,
+				Amount: money.MustParse("1.60", "GBP"),
 				ID:     "1234567810",
 				Note:   "Another test transaction",
 			}
 
 			massPayment.AddItem(itemOne)
 			massPayment.AddItem(itemTwo)
-			massPayment.SetCredentials("user", "password", "signature", "1.0")
 
-			expectedPayload := `CURRENCYCODE=GBP&EMAILSUBJECT=Test+email&L_AMT0=1.50&L_AMT1=1.60&L_EMAIL0=test%40test.com&L_EMAIL1=test%40testtwo.com&L_NOTE0=A+test+transaction&L_NOTE1=Another+test+transaction&L_UNIQUEID0=123456789&L_UNIQUEID1=1234567810&METHOD=MassPay&PWD=password&RECEIVERTYPE=EmailAddress&SIGNATURE=signature&USER=user&VERSION=1.0`
-			payload, _ := massPayment.Serialize()
+			expectedPayload := `CURRENCYCODE=GBP&EMAILSUBJECT=Test+email&L_AMT0=1.50&L_AMT1=1.60&L_EMAIL0=test%40test.com&L_EMAIL1=test%40testtwo.com&L_NOTE0=A+test+transaction&L_NOTE1=Another+test+transaction&L_UNIQUEID0=123456789&L_UNIQUEID1=1234567810&METHOD=MassPay&RECEIVERTYPE=EmailAddress`
+			data, _ := massPayment.Serialize()
 
-			if expectedPayload != payload {
-				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, payload)
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
 			}
 		})
 	})
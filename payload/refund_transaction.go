@@ -0,0 +1,107 @@
+package payload
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+const (
+	// RefundTypeFull refunds the full amount of the transaction.
+	RefundTypeFull = "Full"
+
+	// RefundTypePartial refunds part of the transaction, an amount is
+	// required.
+	RefundTypePartial = "Partial"
+
+	// RefundSourceAny uses any available funding source.
+	RefundSourceAny = "any"
+
+	// RefundSourceDefault uses the funding source configured on the
+	// merchant account.
+	RefundSourceDefault = "default"
+
+	// RefundSourceInstant uses the PayPal balance or an instant funding
+	// source.
+	RefundSourceInstant = "instant"
+
+	// RefundSourceECheck uses an eCheck funding source.
+	RefundSourceECheck = "eCheck"
+)
+
+type (
+	// RefundTransaction payload for refunding a transaction.
+	RefundTransaction struct {
+		Method        string       `nvp_field:"METHOD"`
+		TransactionID string       `nvp_field:"TRANSACTIONID"`
+		RefundType    string       `nvp_field:"REFUNDTYPE"`
+		Amount        money.Amount `nvp_field:"AMT"`
+		CurrencyCode  string       `nvp_field:"CURRENCYCODE"`
+		Note          string       `nvp_field:"NOTE"`
+		MessageID     string       `nvp_field:"MSGSUBID"`
+		RefundSource  string       `nvp_field:"REFUNDSOURCE"`
+		InvoiceID     string       `nvp_field:"INVOICEID"`
+	}
+)
+
+// NewRefundTransaction creates a new RefundTransaction struct refunding the
+// full amount of the transaction.
+func NewRefundTransaction(transactionID string) *RefundTransaction {
+	return &RefundTransaction{
+		Method:        "RefundTransaction",
+		TransactionID: transactionID,
+		RefundType:    RefundTypeFull,
+	}
+}
+
+// NewPartialRefundTransaction creates a new RefundTransaction struct
+// refunding part of the transaction, in the currency of the amount.
+func NewPartialRefundTransaction(transactionID string, amount money.Amount) *RefundTransaction {
+	return &RefundTransaction{
+		Method:        "RefundTransaction",
+		TransactionID: transactionID,
+		RefundType:    RefundTypePartial,
+		Amount:        amount,
+		CurrencyCode:  amount.Currency(),
+	}
+}
+
+// Validate returns ValidationErrors listing every problem with the refund,
+// or nil if it's valid.
+func (rt RefundTransaction) Validate() error {
+	errs := ValidationErrors{}
+
+	if rt.TransactionID == "" {
+		errs.add("TRANSACTIONID", errRequired)
+	}
+
+	switch rt.RefundType {
+	case RefundTypeFull:
+		if !rt.Amount.IsZero() {
+			errs.add("AMT", errors.New("Expected no amount for a full refund"))
+		}
+	case RefundTypePartial:
+		if checkCurrency(&errs, "CURRENCYCODE", rt.CurrencyCode) {
+			checkAmount(&errs, "AMT", rt.Amount, rt.CurrencyCode, true)
+		}
+	default:
+		errs.addf("REFUNDTYPE", "Expected Full or Partial, got '%s'", rt.RefundType)
+	}
+
+	checkMaxLength(&errs, "NOTE", rt.Note, maxNoteLength)
+
+	return errs.err()
+}
+
+// Serialize convert struct into NVP key=value format for the refund,
+// returning ValidationErrors if it's invalid.
+func (rt RefundTransaction) Serialize() (url.Values, error) {
+	if err := rt.Validate(); err != nil {
+		return nil, err
+	}
+	rt.Amount = inCurrency(rt.Amount, rt.CurrencyCode)
+
+	return nvp.Marshal(rt)
+}
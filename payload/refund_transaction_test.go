@@ -0,0 +1,63 @@
+package payload_test
+
+import (
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+func TestRefundTransaction(t *testing.T) {
+	t.Run(".Serialize()", func(t *testing.T) {
+		t.Run("ReturnsErrorWhenNoTransactionID", func(t *testing.T) {
+			_, err := payload.NewRefundTransaction("").Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorWhenPartialRefundHasNoAmount", func(t *testing.T) {
+			_, err := payload.NewPartialRefundTransaction("TX1", money.New(0, "GBP")).Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsErrorWhenFullRefundHasAmount", func(t *testing.T) {
+			refundTransaction := payload.NewRefundTransaction("TX1")
+			refundTransaction.Amount = money.MustParse("10.00", "GBP")
+			_, err := refundTransaction.Serialize()
+
+			if err == nil {
+				t.Fatalf("Expected error, got: %v", err)
+			}
+		})
+
+		t.Run("ReturnsCorrectlySerializedFullRefund", func(t *testing.T) {
+			refundTransaction := payload.NewRefundTransaction("TX1")
+
+			expectedPayload := `METHOD=RefundTransaction&REFUNDTYPE=Full&TRANSACTIONID=TX1`
+			data, _ := refundTransaction.Serialize()
+
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
+			}
+		})
+
+		t.Run("ReturnsCorrectlySerializedPartialRefund", func(t *testing.T) {
+			refundTransaction := payload.NewPartialRefundTransaction("TX1", money.MustParse("5.25", "GBP"))
+			refundTransaction.Note = "Partial refund"
+			refundTransaction.MessageID = "refund-1"
+			refundTransaction.RefundSource = payload.RefundSourceInstant
+
+			expectedPayload := `AMT=5.25&CURRENCYCODE=GBP&METHOD=RefundTransaction&MSGSUBID=refund-1&NOTE=Partial+refund&REFUNDSOURCE=instant&REFUNDTYPE=Partial&TRANSACTIONID=TX1`
+			data, _ := refundTransaction.Serialize()
+
+			if expectedPayload != data.Encode() {
+				t.Fatalf("Expected payload to be: '%s', got '%s'", expectedPayload, data.Encode())
+			}
+		})
+	})
+}
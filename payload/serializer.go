@@ -1,9 +1,17 @@
 package payload
 
+import (
+	"net/url"
+)
+
 type (
-	// Serializer interface for payloads that can be serialized.
+	// Serializer interface for payloads that can be serialized. Credentials
+	// and the API version are added by the client, so payloads only hold
+	// the fields of their method. Validate is called by the client before
+	// any request is made, and should return every problem with the
+	// payload, ideally as ValidationErrors.
 	Serializer interface {
-		Serialize() (string, error)
-		SetCredentials(string, string, string, string)
+		Validate() error
+		Serialize() (url.Values, error)
 	}
 )
@@ -0,0 +1,120 @@
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxEmailSubjectLength = 255
+	maxNoteLength         = 4000
+	maxUniqueIDLength     = 30
+	minPhoneDigits        = 7
+	maxPhoneDigits        = 15
+)
+
+type (
+	// ValidationError problem with a single field of a payload, keyed by
+	// its NVP field name.
+	ValidationError struct {
+		Field string
+		Err   error
+	}
+
+	// ValidationErrors every problem found validating a payload, returned
+	// by Validate.
+	ValidationErrors []ValidationError
+)
+
+var errRequired = errors.New("Expected a value")
+
+// Error Formatted error string based on properties.
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error, e.g. a currency.UnsupportedError.
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Error Formatted error string listing every problem.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, validationError := range e {
+		messages[i] = validationError.Error()
+	}
+
+	return "Invalid payload: " + strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual errors, so errors.Is and errors.As match
+// any of them.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, validationError := range e {
+		errs[i] = validationError
+	}
+
+	return errs
+}
+
+// Fields returns the NVP field names with problems, in order.
+func (e ValidationErrors) Fields() []string {
+	fields := make([]string, len(e))
+	for i, validationError := range e {
+		fields[i] = validationError.Field
+	}
+
+	return fields
+}
+
+func (e *ValidationErrors) add(field string, err error) {
+	*e = append(*e, ValidationError{Field: field, Err: err})
+}
+
+func (e *ValidationErrors) addf(field string, format string, args ...interface{}) {
+	e.add(field, fmt.Errorf(format, args...))
+}
+
+func (e ValidationErrors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func checkMaxLength(errs *ValidationErrors, field string, value string, max int) {
+	if length := utf8.RuneCountInString(value); length > max {
+		errs.addf(field, "Expected at most %d characters, got %d", max, length)
+	}
+}
+
+func validEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+func validPhone(phone string) bool {
+	digits := 0
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return false
+		}
+	}
+
+	return digits >= minPhoneDigits && digits <= maxPhoneDigits
+}
+
+func indexed(field string, index int) string {
+	return fmt.Sprintf("%s%d", field, index)
+}
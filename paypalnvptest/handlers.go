@@ -0,0 +1,289 @@
+package paypalnvptest
+
+import (
+	"net/url"
+	"sort"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
+	"github.com/vidsy/go-paypalnvp/payload"
+)
+
+type (
+	checkout struct {
+		request   payload.SetExpressCheckout
+		completed bool
+	}
+)
+
+var (
+	errSecurityHeader = apiError{
+		code:         "10002",
+		shortMessage: "Security error",
+		longMessage:  "Security header is not valid",
+	}
+
+	errUnsupportedMethod = apiError{
+		code:         "81002",
+		shortMessage: "Unspecified Method",
+		longMessage:  "Method Specified is not Supported",
+	}
+
+	errInvalidArgument = apiError{
+		code:         "10004",
+		shortMessage: "Transaction refused because of an invalid argument. See additional error messages for details.",
+		longMessage:  "Transaction refused because of an invalid argument. See additional error messages for details.",
+	}
+
+	errInsufficientFunds = apiError{
+		code:         "10321",
+		shortMessage: "Insufficient funds",
+		longMessage:  "The account does not have sufficient funds to do this masspay",
+	}
+
+	errInvalidTransactionID = apiError{
+		code:         "10004",
+		shortMessage: "Transaction refused because of an invalid argument. See additional error messages for details.",
+		longMessage:  "The transaction id is not valid",
+	}
+
+	errRefundExceedsAmount = apiError{
+		code:         "10009",
+		shortMessage: "Transaction refused",
+		longMessage:  "The partial refund amount must be less than or equal to the remaining amount",
+	}
+
+	errInvalidToken = apiError{
+		code:         "10410",
+		shortMessage: "Invalid token",
+		longMessage:  "Invalid token.",
+	}
+
+	errCheckoutCompleted = apiError{
+		code:         "10415",
+		shortMessage: "Transaction refused because of an invalid argument. See additional error messages for details.",
+		longMessage:  "A successful transaction has already been completed for this token.",
+	}
+)
+
+func (s *Server) massPay(request url.Values) (interface{}, error) {
+	massPayment := payload.MassPayment{}
+	if err := nvp.Unmarshal(request, &massPayment); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	if massPayment.CurrencyCode == "" || len(massPayment.Items) == 0 {
+		return nil, errInvalidArgument
+	}
+
+	total := massPayment.Total()
+	if s.balance(massPayment.CurrencyCode).Cmp(total) < 0 {
+		return nil, errInsufficientFunds
+	}
+
+	s.adjustBalance(massPayment.CurrencyCode, money.Amount{}.Sub(total))
+	for _, item := range massPayment.Items {
+		receiver := item.Email
+		switch massPayment.ReceiverType {
+		case payload.ReceiverTypePhone:
+			receiver = item.Phone
+		case payload.ReceiverTypeUserID:
+			receiver = item.UserID
+		}
+
+		s.addTransaction(Transaction{
+			Type:         TransactionTypeMassPay,
+			Receiver:     receiver,
+			UniqueID:     item.ID,
+			Amount:       item.Amount.WithCurrency(massPayment.CurrencyCode),
+			CurrencyCode: massPayment.CurrencyCode,
+		})
+	}
+
+	return paypalnvp.MassPayResponse{Response: s.success(request)}, nil
+}
+
+func (s *Server) getBalance(request url.Values) (interface{}, error) {
+	getBalance := payload.GetBalance{}
+	if err := nvp.Unmarshal(request, &getBalance); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	currencies := []string{s.PrimaryCurrency}
+	if getBalance.ReturnAllCurrencies {
+		currencies = currencies[:0]
+		for currency := range s.balances {
+			currencies = append(currencies, currency)
+		}
+		sort.Strings(currencies)
+	}
+
+	response := paypalnvp.GetBalanceResponse{Response: s.success(request)}
+	for _, currency := range currencies {
+		response.Balances = append(response.Balances, paypalnvp.Balance{
+			Amount:       s.balance(currency),
+			CurrencyCode: currency,
+		})
+	}
+
+	return response, nil
+}
+
+func (s *Server) refundTransaction(request url.Values) (interface{}, error) {
+	refundTransaction := payload.RefundTransaction{}
+	if err := nvp.Unmarshal(request, &refundTransaction); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	if refundTransaction.MessageID != "" {
+		if previous, ok := s.refunds[refundTransaction.MessageID]; ok {
+			response := paypalnvp.RefundTransactionResponse{}
+			nvp.Unmarshal(previous, &response)
+			response.Response = s.success(request)
+
+			return response, nil
+		}
+	}
+
+	transaction, ok := s.transactions[refundTransaction.TransactionID]
+	if !ok || transaction.Type == TransactionTypeRefund {
+		return nil, errInvalidTransactionID
+	}
+
+	remaining := transaction.Amount.Sub(transaction.RefundedAmount)
+	amount := remaining
+	if refundTransaction.RefundType == payload.RefundTypePartial {
+		amount = refundTransaction.Amount.WithCurrency(transaction.CurrencyCode)
+	}
+
+	if amount.Cmp(money.Amount{}) <= 0 || amount.Cmp(remaining) > 0 {
+		return nil, errRefundExceedsAmount
+	}
+
+	if s.balance(transaction.CurrencyCode).Cmp(amount) < 0 {
+		return nil, errInsufficientFunds
+	}
+
+	transaction.RefundedAmount = transaction.RefundedAmount.Add(amount)
+	s.adjustBalance(transaction.CurrencyCode, money.Amount{}.Sub(amount))
+	refund := s.addTransaction(Transaction{
+		Type:         TransactionTypeRefund,
+		Receiver:     transaction.Receiver,
+		Amount:       amount,
+		CurrencyCode: transaction.CurrencyCode,
+		ParentID:     transaction.ID,
+	})
+
+	response := paypalnvp.RefundTransactionResponse{
+		Response:            s.success(request),
+		RefundTransactionID: refund.ID,
+		GrossRefundAmount:   amount,
+		NetRefundAmount:     amount,
+		TotalRefundedAmount: transaction.RefundedAmount,
+		CurrencyCode:        transaction.CurrencyCode,
+		RefundStatus:        paypalnvp.RefundStatusInstant,
+		PendingReason:       "None",
+		MessageID:           refundTransaction.MessageID,
+	}
+
+	if refundTransaction.MessageID != "" {
+		s.refunds[refundTransaction.MessageID], _ = nvp.Marshal(response)
+	}
+
+	return response, nil
+}
+
+func (s *Server) setExpressCheckout(request url.Values) (interface{}, error) {
+	setExpressCheckout := payload.SetExpressCheckout{}
+	if err := nvp.Unmarshal(request, &setExpressCheckout); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	if setExpressCheckout.ReturnURL == "" || setExpressCheckout.CancelURL == "" || len(setExpressCheckout.PaymentRequests) == 0 {
+		return nil, errInvalidArgument
+	}
+
+	token := s.nextID("EC-")
+	s.checkouts[token] = &checkout{request: setExpressCheckout}
+
+	return paypalnvp.SetExpressCheckoutResponse{
+		Response: s.success(request),
+		Token:    token,
+	}, nil
+}
+
+func (s *Server) getExpressCheckoutDetails(request url.Values) (interface{}, error) {
+	token := request.Get("TOKEN")
+	checkout, ok := s.checkouts[token]
+	if !ok {
+		return nil, errInvalidToken
+	}
+
+	status := "PaymentActionNotInitiated"
+	if checkout.completed {
+		status = "PaymentActionCompleted"
+	}
+
+	return paypalnvp.GetExpressCheckoutDetailsResponse{
+		Response:        s.success(request),
+		Token:           token,
+		CheckoutStatus:  status,
+		PayerID:         DefaultPayerID,
+		PayerStatus:     "verified",
+		Email:           DefaultPayerEmail,
+		PaymentRequests: checkout.request.PaymentRequests,
+	}, nil
+}
+
+func (s *Server) doExpressCheckoutPayment(request url.Values) (interface{}, error) {
+	doExpressCheckoutPayment := payload.DoExpressCheckoutPayment{}
+	if err := nvp.Unmarshal(request, &doExpressCheckoutPayment); err != nil {
+		return nil, errInvalidArgument
+	}
+
+	checkout, ok := s.checkouts[doExpressCheckoutPayment.Token]
+	if !ok || doExpressCheckoutPayment.PayerID != DefaultPayerID {
+		return nil, errInvalidToken
+	}
+
+	if checkout.completed {
+		return nil, errCheckoutCompleted
+	}
+
+	if len(doExpressCheckoutPayment.PaymentRequests) == 0 {
+		return nil, errInvalidArgument
+	}
+
+	checkout.completed = true
+	response := paypalnvp.DoExpressCheckoutPaymentResponse{
+		Response: s.success(request),
+		Token:    doExpressCheckoutPayment.Token,
+	}
+
+	for _, paymentRequest := range doExpressCheckoutPayment.PaymentRequests {
+		paymentRequest.Amount = paymentRequest.Amount.WithCurrency(paymentRequest.CurrencyCode)
+		s.adjustBalance(paymentRequest.CurrencyCode, paymentRequest.Amount)
+		transaction := s.addTransaction(Transaction{
+			Type:             TransactionTypeExpressCheckout,
+			Receiver:         paymentRequest.SellerID,
+			Amount:           paymentRequest.Amount,
+			CurrencyCode:     paymentRequest.CurrencyCode,
+			PaymentRequestID: paymentRequest.ID,
+		})
+
+		response.PaymentInfo = append(response.PaymentInfo, paypalnvp.PaymentInfo{
+			TransactionID:    transaction.ID,
+			TransactionType:  "expresscheckout",
+			PaymentType:      "instant",
+			Amount:           paymentRequest.Amount,
+			CurrencyCode:     paymentRequest.CurrencyCode,
+			PaymentStatus:    "Completed",
+			PendingReason:    "None",
+			PaymentRequestID: paymentRequest.ID,
+			Acknowledgement:  paypalnvp.AckSuccess,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,263 @@
+// Package paypalnvptest provides a fake PayPal NVP server for integration
+// tests, keeping balances and transactions in memory.
+package paypalnvptest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+const (
+	// DefaultUser API user accepted by a new server.
+	DefaultUser = "user"
+
+	// DefaultPassword API password accepted by a new server.
+	DefaultPassword = "password"
+
+	// DefaultSignature API signature accepted by a new server.
+	DefaultSignature = "signature"
+
+	// DefaultPayerID payer ID returned for express checkouts.
+	DefaultPayerID = "TESTBUYERID01"
+
+	// DefaultPayerEmail payer email returned for express checkouts.
+	DefaultPayerEmail = "buyer@example.com"
+
+	// TransactionTypeMassPay transaction sent by a MassPay request.
+	TransactionTypeMassPay = "MassPay"
+
+	// TransactionTypeExpressCheckout transaction received by a
+	// DoExpressCheckoutPayment request.
+	TransactionTypeExpressCheckout = "ExpressCheckout"
+
+	// TransactionTypeRefund transaction sent by a RefundTransaction request.
+	TransactionTypeRefund = "Refund"
+)
+
+type (
+	// Server fake NVP server. Credentials, balances and the primary
+	// currency can be changed before requests are made.
+	Server struct {
+		*httptest.Server
+		User            string
+		Password        string
+		Signature       string
+		PrimaryCurrency string
+
+		mu           sync.Mutex
+		sequence     int
+		balances     map[string]money.Amount
+		transactions map[string]*Transaction
+		checkouts    map[string]*checkout
+		refunds      map[string]url.Values
+	}
+
+	// Transaction record of money moved by the server.
+	Transaction struct {
+		ID               string
+		Type             string
+		Receiver         string
+		UniqueID         string
+		Amount           money.Amount
+		CurrencyCode     string
+		RefundedAmount   money.Amount
+		ParentID         string
+		PaymentRequestID string
+	}
+
+	handlerFunc func(s *Server, request url.Values) (interface{}, error)
+
+	apiError struct {
+		code         string
+		shortMessage string
+		longMessage  string
+	}
+)
+
+var handlers = map[string]handlerFunc{
+	"MassPay":                   (*Server).massPay,
+	"GetBalance":                (*Server).getBalance,
+	"RefundTransaction":         (*Server).refundTransaction,
+	"SetExpressCheckout":        (*Server).setExpressCheckout,
+	"GetExpressCheckoutDetails": (*Server).getExpressCheckoutDetails,
+	"DoExpressCheckoutPayment":  (*Server).doExpressCheckoutPayment,
+}
+
+// NewServer starts a new fake NVP server accepting the default credentials.
+// Callers should Close it when finished.
+func NewServer() *Server {
+	s := &Server{
+		User:            DefaultUser,
+		Password:        DefaultPassword,
+		Signature:       DefaultSignature,
+		PrimaryCurrency: "USD",
+		balances:        make(map[string]money.Amount),
+		transactions:    make(map[string]*Transaction),
+		checkouts:       make(map[string]*checkout),
+		refunds:         make(map[string]url.Values),
+	}
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// NewClient creates a new paypalnvp.Client pointed at the server with its
+// credentials.
+func (s *Server) NewClient() *paypalnvp.Client {
+	client, _ := paypalnvp.NewClient(s.Server.Client(), paypalnvp.Sandbox, s.User, s.Password, s.Signature)
+	client.Endpoint = s.URL
+
+	return client
+}
+
+// SetBalance sets the balance held in the currency of the amount.
+func (s *Server) SetBalance(amount money.Amount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balances[amount.Currency()] = amount
+}
+
+// Balance returns the balance held in a currency.
+func (s *Server) Balance(currency string) money.Amount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.balance(currency)
+}
+
+// Transaction returns the transaction with the given ID.
+func (s *Server) Transaction(id string) (Transaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transaction, ok := s.transactions[id]
+	if !ok {
+		return Transaction{}, false
+	}
+
+	return *transaction, true
+}
+
+// Transactions returns every transaction ordered by ID.
+func (s *Server) Transactions() []Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transactions := make([]Transaction, 0, len(s.transactions))
+	for _, transaction := range s.transactions {
+		transactions = append(transactions, *transaction)
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	return transactions
+}
+
+// ServeHTTP handles an NVP request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	response, err := s.handle(r.PostForm)
+	s.mu.Unlock()
+
+	data := url.Values{}
+	if err == nil {
+		data, err = nvp.Marshal(response)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, data.Encode())
+}
+
+func (s *Server) handle(request url.Values) (interface{}, error) {
+	if request.Get("USER") != s.User || request.Get("PWD") != s.Password || request.Get("SIGNATURE") != s.Signature {
+		return s.failure(request, errSecurityHeader), nil
+	}
+
+	handler, ok := handlers[request.Get("METHOD")]
+	if !ok {
+		return s.failure(request, errUnsupportedMethod), nil
+	}
+
+	response, err := handler(s, request)
+	if apiErr, ok := err.(apiError); ok {
+		return s.failure(request, apiErr), nil
+	}
+
+	return response, err
+}
+
+func (s *Server) success(request url.Values) paypalnvp.Response {
+	return paypalnvp.Response{
+		Acknowledgement: paypalnvp.AckSuccess,
+		CorrelationID:   s.nextID("CORRELATION"),
+		TimeStamp:       time.Now().UTC(),
+		Version:         request.Get("VERSION"),
+		Build:           "000000",
+	}
+}
+
+func (s *Server) failure(request url.Values, err apiError) paypalnvp.Response {
+	response := s.success(request)
+	response.Acknowledgement = paypalnvp.AckFailure
+	response.Errors = []paypalnvp.ResponseError{
+		{
+			Code:         err.code,
+			ShortMessage: err.shortMessage,
+			LongMessage:  err.longMessage,
+			SeverityCode: "Error",
+		},
+	}
+
+	return response
+}
+
+func (s *Server) nextID(prefix string) string {
+	s.sequence++
+	return fmt.Sprintf("%s%08d", prefix, s.sequence)
+}
+
+func (s *Server) addTransaction(transaction Transaction) *Transaction {
+	transaction.ID = s.nextID("TX")
+	s.transactions[transaction.ID] = &transaction
+
+	return &transaction
+}
+
+func (s *Server) balance(currency string) money.Amount {
+	return s.balances[currency].WithCurrency(currency)
+}
+
+func (s *Server) adjustBalance(currency string, amount money.Amount) {
+	s.balances[currency] = s.balance(currency).Add(amount)
+}
+
+func (e apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.longMessage)
+}
@@ -0,0 +1,180 @@
+package paypalnvptest_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+	"github.com/vidsy/go-paypalnvp/money"
+	"github.com/vidsy/go-paypalnvp/payload"
+	"github.com/vidsy/go-paypalnvp/paypalnvptest"
+)
+
+type (
+	UnsupportedPayload struct{}
+)
+
+func (up UnsupportedPayload) Validate() error {
+	return nil
+}
+
+func (up UnsupportedPayload) Serialize() (url.Values, error) {
+	return url.Values{"METHOD": {"DoDirectPayment"}}, nil
+}
+
+func NewMassPayment(amounts ...string) *payload.MassPayment {
+	massPayment := payload.NewMassPayment("GBP", payload.ReceiverTypeEmail)
+	for _, amount := range amounts {
+		massPayment.AddItem(payload.MassPaymentItem{
+			Email:  "creator@example.com",
+			Amount: money.MustParse(amount, "GBP"),
+		})
+	}
+
+	return massPayment
+}
+
+func TestServer(t *testing.T) {
+	t.Run("RejectsInvalidCredentials", func(t *testing.T) {
+		server := paypalnvptest.NewServer()
+		defer server.Close()
+
+		client := server.NewClient()
+		client.Credentials.Password = "wrong"
+		response, _ := client.Execute(payload.NewGetBalance(true))
+
+		if response.Successful() || response.Errors[0].Code != "10002" {
+			t.Fatalf("Expected security error 10002, got: %v", response.Errors)
+		}
+	})
+
+	t.Run("RejectsUnsupportedMethod", func(t *testing.T) {
+		server := paypalnvptest.NewServer()
+		defer server.Close()
+
+		response, _ := server.NewClient().Execute(UnsupportedPayload{})
+
+		if response.Successful() || response.Errors[0].Code != "81002" {
+			t.Fatalf("Expected unsupported method error 81002, got: %v", response.Errors)
+		}
+	})
+
+	t.Run("RejectsUnknownToken", func(t *testing.T) {
+		server := paypalnvptest.NewServer()
+		defer server.Close()
+
+		response, _ := server.NewClient().Execute(payload.NewGetExpressCheckoutDetails("EC-UNKNOWN"))
+
+		if response.Successful() || response.Errors[0].Code != "10410" {
+			t.Fatalf("Expected invalid token error 10410, got: %v", response.Errors)
+		}
+	})
+
+	t.Run("MassPay", func(t *testing.T) {
+		t.Run("DeductsBalanceAndRecordsTransactions", func(t *testing.T) {
+			server := paypalnvptest.NewServer()
+			defer server.Close()
+			server.SetBalance(money.MustParse("100.00", "GBP"))
+
+			response := paypalnvp.MassPayResponse{}
+			err := server.NewClient().ExecuteInto(NewMassPayment("10.50", "20.00"), &response)
+
+			if err != nil || !response.Successful() {
+				t.Fatalf("Expected successful response, got: %v, %v", err, response.Errors)
+			}
+
+			if server.Balance("GBP").String() != "69.50" {
+				t.Fatalf("Expected balance to be 69.50, got: %s", server.Balance("GBP"))
+			}
+
+			if len(server.Transactions()) != 2 {
+				t.Fatalf("Expected 2 transactions, got: %d", len(server.Transactions()))
+			}
+		})
+
+		t.Run("FailsWithInsufficientFunds", func(t *testing.T) {
+			server := paypalnvptest.NewServer()
+			defer server.Close()
+			server.SetBalance(money.MustParse("10.00", "GBP"))
+
+			response, _ := server.NewClient().Execute(NewMassPayment("10.50"))
+
+			if response.Successful() || response.Errors[0].Code != "10321" {
+				t.Fatalf("Expected insufficient funds error 10321, got: %v", response.Errors)
+			}
+
+			if server.Balance("GBP").String() != "10.00" {
+				t.Fatalf("Expected balance to be unchanged, got: %s", server.Balance("GBP"))
+			}
+		})
+	})
+
+	t.Run("GetBalance", func(t *testing.T) {
+		t.Run("ReturnsAllCurrencies", func(t *testing.T) {
+			server := paypalnvptest.NewServer()
+			defer server.Close()
+			server.SetBalance(money.MustParse("100.00", "GBP"))
+			server.SetBalance(money.MustParse("20.00", "USD"))
+
+			response := paypalnvp.GetBalanceResponse{}
+			server.NewClient().ExecuteInto(payload.NewGetBalance(true), &response)
+
+			balances := response.ByCurrency()
+			if len(balances) != 2 || balances["GBP"].String() != "100.00" || balances["USD"].String() != "20.00" {
+				t.Fatalf("Expected GBP 100.00 and USD 20.00, got: %v", balances)
+			}
+		})
+
+		t.Run("SupportsCheckFunds", func(t *testing.T) {
+			server := paypalnvptest.NewServer()
+			defer server.Close()
+			server.SetBalance(money.MustParse("5.00", "GBP"))
+
+			err := server.NewClient().CheckFunds(NewMassPayment("10.00"))
+			if _, ok := err.(paypalnvp.InsufficientFundsError); !ok {
+				t.Fatalf("Expected InsufficientFundsError, got: %v", err)
+			}
+		})
+	})
+
+	t.Run("ExpressCheckoutAndRefund", func(t *testing.T) {
+		server := paypalnvptest.NewServer()
+		defer server.Close()
+		client := server.NewClient()
+
+		setExpressCheckout := payload.NewSetExpressCheckout("https://example.com/return", "https://example.com/cancel")
+		setExpressCheckout.AddPaymentRequest(payload.PaymentRequest{Amount: money.MustParse("15.00", "GBP"), CurrencyCode: "GBP"})
+		checkout := paypalnvp.SetExpressCheckoutResponse{}
+		if err := client.ExecuteInto(setExpressCheckout, &checkout); err != nil || checkout.Token == "" {
+			t.Fatalf("Expected token, got: %v, %v", err, checkout.Errors)
+		}
+
+		details := paypalnvp.GetExpressCheckoutDetailsResponse{}
+		client.ExecuteInto(payload.NewGetExpressCheckoutDetails(checkout.Token), &details)
+		if details.PayerID != paypalnvptest.DefaultPayerID {
+			t.Fatalf("Expected PayerID to be '%s', got: '%s'", paypalnvptest.DefaultPayerID, details.PayerID)
+		}
+
+		doExpressCheckoutPayment := payload.NewDoExpressCheckoutPayment(checkout.Token, details.PayerID)
+		doExpressCheckoutPayment.AddPaymentRequest(payload.PaymentRequest{Amount: money.MustParse("15.00", "GBP"), CurrencyCode: "GBP"})
+		payment := paypalnvp.DoExpressCheckoutPaymentResponse{}
+		client.ExecuteInto(doExpressCheckoutPayment, &payment)
+		if len(payment.TransactionIDs()) != 1 || server.Balance("GBP").String() != "15.00" {
+			t.Fatalf("Expected 1 transaction and balance of 15.00, got: %v, %s", payment.TransactionIDs(), server.Balance("GBP"))
+		}
+
+		refundTransaction := payload.NewPartialRefundTransaction(payment.TransactionIDs()[0], money.MustParse("5.00", "GBP"))
+		refundTransaction.MessageID = "refund-1"
+		refund := paypalnvp.RefundTransactionResponse{}
+		client.ExecuteInto(refundTransaction, &refund)
+		client.ExecuteInto(refundTransaction, &refund)
+
+		if refund.RefundTransactionID == "" || refund.GrossRefundAmount.String() != "5.00" {
+			t.Fatalf("Expected refund of 5.00, got: '%s', %s", refund.RefundTransactionID, refund.GrossRefundAmount)
+		}
+
+		if server.Balance("GBP").String() != "10.00" {
+			t.Fatalf("Expected duplicate refund to be ignored and balance to be 10.00, got: %s", server.Balance("GBP"))
+		}
+	})
+}
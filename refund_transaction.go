@@ -0,0 +1,37 @@
+package paypalnvp
+
+import (
+	"github.com/vidsy/go-paypalnvp/money"
+)
+
+const (
+	// RefundStatusInstant the refund has been completed.
+	RefundStatusInstant = "Instant"
+
+	// RefundStatusDelayed the refund is pending, see PendingReason.
+	RefundStatusDelayed = "Delayed"
+)
+
+type (
+	// RefundTransactionResponse struct for response from a
+	// RefundTransaction request.
+	RefundTransactionResponse struct {
+		Response
+		RefundTransactionID string       `nvp_field:"REFUNDTRANSACTIONID"`
+		FeeRefundAmount     money.Amount `nvp_field:"FEEREFUNDAMT"`
+		GrossRefundAmount   money.Amount `nvp_field:"GROSSREFUNDAMT"`
+		NetRefundAmount     money.Amount `nvp_field:"NETREFUNDAMT"`
+		TotalRefundedAmount money.Amount `nvp_field:"TOTALREFUNDEDAMOUNT"`
+		CurrencyCode        string       `nvp_field:"CURRENCYCODE"`
+		RefundStatus        string       `nvp_field:"REFUNDSTATUS"`
+		PendingReason       string       `nvp_field:"PENDINGREASON"`
+		MessageID           string       `nvp_field:"MSGSUBID"`
+	}
+)
+
+func (r *RefundTransactionResponse) setCurrencies() {
+	r.FeeRefundAmount = r.FeeRefundAmount.WithCurrency(r.CurrencyCode)
+	r.GrossRefundAmount = r.GrossRefundAmount.WithCurrency(r.CurrencyCode)
+	r.NetRefundAmount = r.NetRefundAmount.WithCurrency(r.CurrencyCode)
+	r.TotalRefundedAmount = r.TotalRefundedAmount.WithCurrency(r.CurrencyCode)
+}
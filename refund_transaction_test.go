@@ -0,0 +1,72 @@
+package paypalnvp_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+func TestRefundTransactionResponse(t *testing.T) {
+	t.Run("DecodesRefundAmounts", func(t *testing.T) {
+		data := `ACK=Success&REFUNDTRANSACTIONID=RTX1&FEEREFUNDAMT=0.15&GROSSREFUNDAMT=5.25&NETREFUNDAMT=5.10&TOTALREFUNDEDAMOUNT=5.25&CURRENCYCODE=GBP&REFUNDSTATUS=Instant&PENDINGREASON=None`
+		httpResponse := &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+			StatusCode: 200,
+		}
+
+		response, _ := paypalnvp.NewResponse(httpResponse)
+		refund := paypalnvp.RefundTransactionResponse{}
+		if err := response.Decode(&refund); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if refund.RefundTransactionID != "RTX1" {
+			t.Fatalf("Expected RefundTransactionID to be 'RTX1', got: '%s'", refund.RefundTransactionID)
+		}
+
+		if refund.GrossRefundAmount.String() != "5.25" || refund.NetRefundAmount.String() != "5.10" || refund.FeeRefundAmount.String() != "0.15" {
+			t.Fatalf("Expected amounts 5.25, 5.10 and 0.15, got: %s, %s, %s", refund.GrossRefundAmount, refund.NetRefundAmount, refund.FeeRefundAmount)
+		}
+
+		if refund.RefundStatus != paypalnvp.RefundStatusInstant {
+			t.Fatalf("Expected RefundStatus to be 'Instant', got: '%s'", refund.RefundStatus)
+		}
+	})
+
+	t.Run("IgnoresEmptyAmounts", func(t *testing.T) {
+		data := `ACK=Success&REFUNDTRANSACTIONID=RTX1&FEEREFUNDAMT=&GROSSREFUNDAMT=5.25&NETREFUNDAMT=5.25&CURRENCYCODE=GBP`
+		httpResponse := &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+			StatusCode: 200,
+		}
+
+		response, _ := paypalnvp.NewResponse(httpResponse)
+		refund := paypalnvp.RefundTransactionResponse{}
+		if err := response.Decode(&refund); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if refund.GrossRefundAmount.String() != "5.25" || !refund.FeeRefundAmount.IsZero() {
+			t.Fatalf("Expected gross 5.25 and no fee, got: %s, %s", refund.GrossRefundAmount, refund.FeeRefundAmount)
+		}
+	})
+
+	t.Run("DecodesAmountsInCurrency", func(t *testing.T) {
+		data := `ACK=Success&GROSSREFUNDAMT=1000&NETREFUNDAMT=1000&CURRENCYCODE=JPY`
+		httpResponse := &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+			StatusCode: 200,
+		}
+
+		response, _ := paypalnvp.NewResponse(httpResponse)
+		refund := paypalnvp.RefundTransactionResponse{}
+		response.Decode(&refund)
+
+		if refund.GrossRefundAmount.String() != "1000" || refund.GrossRefundAmount.Currency() != "JPY" || refund.FeeRefundAmount.Currency() != "JPY" {
+			t.Fatalf("Expected JPY amounts, got: %s %s", refund.GrossRefundAmount, refund.GrossRefundAmount.Currency())
+		}
+	})
+}
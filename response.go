@@ -1,31 +1,80 @@
 package paypalnvp
 
 import (
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"reflect"
-	"strings"
 	"time"
+
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+const (
+	// AckSuccess the request was successful.
+	AckSuccess Acknowledgement = "Success"
+
+	// AckSuccessWithWarning the request was successful, but PayPal
+	// returned warnings.
+	AckSuccessWithWarning Acknowledgement = "SuccessWithWarning"
+
+	// AckFailure the request failed.
+	AckFailure Acknowledgement = "Failure"
+
+	// AckFailureWithWarning the request failed, and PayPal also returned
+	// warnings.
+	AckFailureWithWarning Acknowledgement = "FailureWithWarning"
+
+	// AckWarning the request was successful, but PayPal returned warnings.
+	// Older API versions return it in place of AckSuccessWithWarning.
+	AckWarning Acknowledgement = "Warning"
+
+	// AckPartialSuccess some of the payments of a parallel payment
+	// succeeded and others failed.
+	AckPartialSuccess Acknowledgement = "PartialSuccess"
+
+	severityWarning = "Warning"
 )
 
 type (
+	// Acknowledgement ACK value of a response, indicating whether the
+	// request succeeded.
+	Acknowledgement string
+
 	// Response struct for response from NVP request.
 	Response struct {
-		*http.Response
+		*http.Response    `nvp_field:"-"`
 		ParsedQueryParams *url.Values
-		Acknowledgement   string    `nvp_field:"ACK"`
-		CorrelationID     string    `nvp_field:"CORRELATIONID"`
-		TimeStamp         time.Time `nvp_field:"TIMESTAMP"`
-		Version           string    `nvp_field:"VERSION"`
-		Build             string    `nvp_field:"BUILD"`
-		Errors            []ResponseError
+		Acknowledgement   Acknowledgement `nvp_field:"ACK"`
+		CorrelationID     string          `nvp_field:"CORRELATIONID"`
+		TimeStamp         time.Time       `nvp_field:"TIMESTAMP"`
+		Version           string          `nvp_field:"VERSION"`
+		Build             string          `nvp_field:"BUILD"`
+
+		// Errors errors returned by PayPal, excluding warnings.
+		Errors []ResponseError
+
+		// Warnings errors returned by PayPal with a severity of Warning.
+		Warnings []ResponseError `nvp_field:"-"`
+
+		Attempts int
+	}
+
+	responseSetter interface {
+		setResponse(*Response)
+	}
+
+	// currencySetter implemented by responses holding amounts, to set the
+	// currency of each from the currency code returned alongside it.
+	currencySetter interface {
+		setCurrencies()
 	}
 )
 
-// NewResponse Creates new response from net/http response.
+// NewResponse Creates new response from net/http response. If a field
+// cannot be decoded the response is returned with the remaining fields
+// decoded, along with the nvp.DecodeError, as PayPal may already have
+// processed the request.
 func NewResponse(httpResponse *http.Response) (*Response, error) {
 	response := &Response{Response: httpResponse}
 	data, err := response.parseBody()
@@ -33,36 +82,112 @@ func NewResponse(httpResponse *http.Response) (*Response, error) {
 		return nil, err
 	}
 	response.ParsedQueryParams = data
-	response.mapFields()
 
-	return response, nil
+	err = nvp.Unmarshal(*data, response)
+	response.splitWarnings()
+
+	return response, err
+}
+
+// IsSuccess indicates if the ACK reports a successful request, with or
+// without warnings.
+func (a Acknowledgement) IsSuccess() bool {
+	return a == AckSuccess || a == AckSuccessWithWarning || a == AckWarning
+}
+
+// IsWarning indicates if the ACK reports warnings.
+func (a Acknowledgement) IsWarning() bool {
+	return a == AckSuccessWithWarning || a == AckFailureWithWarning || a == AckWarning
+}
+
+// IsFailure indicates if the ACK reports a failed request, with or without
+// warnings. Partial successes are not failures.
+func (a Acknowledgement) IsFailure() bool {
+	return a == AckFailure || a == AckFailureWithWarning
 }
 
-// Successful indicates if the request was valid based on status code and
-// NVP response fields.
+// Successful indicates if the request was successful based on status code
+// and ACK, which may still report warnings. A response without an HTTP
+// response, such as one returned by an Interceptor, is judged on its ACK.
 func (r Response) Successful() bool {
-	if r.StatusCode != 200 {
-		return false
-	}
+	return r.statusOK() && r.Acknowledgement.IsSuccess()
+}
+
+// IsWarning indicates if PayPal returned warnings, whether the request
+// succeeded or not.
+func (r Response) IsWarning() bool {
+	return r.Acknowledgement.IsWarning() || len(r.Warnings) > 0
+}
+
+// IsFailure indicates if the request failed, based on status code and ACK.
+// A response without an HTTP response is judged on its ACK.
+func (r Response) IsFailure() bool {
+	return !r.statusOK() || r.Acknowledgement.IsFailure()
+}
+
+func (r Response) statusOK() bool {
+	return r.Response == nil || r.StatusCode == http.StatusOK
+}
 
-	if r.ErrorCount() > 0 {
-		return false
+// statusCode HTTP status code of the response, 0 when there is no HTTP
+// response.
+func (r Response) statusCode() int {
+	if r.Response == nil {
+		return 0
 	}
 
-	return true
+	return r.StatusCode
 }
 
-// ErrorCount count of errors returned in response.
+// ErrorCount count of errors returned in response, excluding warnings.
 func (r *Response) ErrorCount() int {
-	errorCount := 0
+	return len(r.Errors)
+}
+
+// Decode maps the response data onto v, which must be a pointer to a
+// struct with nvp_field tags. Method specific responses embedding Response
+// also have the common fields populated, even if a field fails to decode,
+// and their amounts in the currency returned with them.
+func (r *Response) Decode(v interface{}) error {
+	data := url.Values{}
 	if r.ParsedQueryParams != nil {
-		for key, _ := range *r.ParsedQueryParams {
-			if strings.Contains(key, "L_ERRORCODE") {
-				errorCount += 1
-			}
+		data = *r.ParsedQueryParams
+	}
+
+	err := nvp.Unmarshal(data, v)
+	if _, invalid := err.(nvp.InvalidUnmarshalError); invalid {
+		return err
+	}
+
+	if setter, ok := v.(responseSetter); ok {
+		setter.setResponse(r)
+	}
+
+	if setter, ok := v.(currencySetter); ok {
+		setter.setCurrencies()
+	}
+
+	return err
+}
+
+func (r *Response) setResponse(response *Response) {
+	*r = *response
+}
+
+func (r *Response) splitWarnings() {
+	responseErrors := []ResponseError{}
+	for _, responseError := range r.Errors {
+		if responseError.SeverityCode == severityWarning {
+			r.Warnings = append(r.Warnings, responseError)
+			continue
 		}
+
+		responseErrors = append(responseErrors, responseError)
+	}
+
+	if len(responseErrors) != len(r.Errors) {
+		r.Errors = responseErrors
 	}
-	return errorCount
 }
 
 func (r *Response) parseBody() (*url.Values, error) {
@@ -82,51 +207,3 @@ func (r *Response) parseBody() (*url.Values, error) {
 
 	return &data, nil
 }
-
-func (r *Response) mapFields() {
-	mapping := r.tagToFieldMapping()
-
-	for key, values := range *r.ParsedQueryParams {
-		if fieldName, exists := mapping[key]; exists {
-			responseValue := reflect.ValueOf(r)
-			field := responseValue.Elem().FieldByName(fieldName)
-			switch field.Kind() {
-			case reflect.String:
-				field.SetString(values[0])
-			}
-		}
-	}
-
-	for i := 0; i < r.ErrorCount(); i++ {
-		errorItem := ResponseError{}
-		errorItemType := reflect.TypeOf(errorItem)
-		errorItemValue := reflect.ValueOf(&errorItem)
-
-		for ii := 0; ii < errorItemType.NumField(); ii++ {
-			field := errorItemType.Field(ii)
-			if fieldTag, ok := field.Tag.Lookup("nvp_field"); ok {
-				fieldValue := errorItemValue.Elem().FieldByName(field.Name)
-				switch fieldValue.Kind() {
-				case reflect.String:
-					fieldValue.SetString(r.ParsedQueryParams.Get(fmt.Sprintf(fieldTag, i)))
-				}
-			}
-		}
-
-		r.Errors = append(r.Errors, errorItem)
-	}
-}
-
-func (r *Response) tagToFieldMapping() map[string]string {
-	mapping := make(map[string]string)
-	itemType := reflect.TypeOf(*r)
-
-	for i := 0; i < itemType.NumField(); i++ {
-		field := itemType.Field(i)
-		if fieldTag, ok := field.Tag.Lookup("nvp_field"); ok {
-			mapping[fieldTag] = field.Name
-		}
-	}
-
-	return mapping
-}
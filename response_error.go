@@ -29,3 +29,10 @@ func (r ResponseError) Error() string {
 	)
 
 }
+
+// Is matches the sentinel error for the category of the error code, see
+// Category.
+func (r ResponseError) Is(target error) bool {
+	sentinel, ok := categorySentinels[r.Category()]
+	return ok && sentinel == target
+}
@@ -28,4 +28,40 @@ func TestResponseError(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Catalogue", func(t *testing.T) {
+		t.Run("ClassifiesErrorCodes", func(t *testing.T) {
+			tests := []struct {
+				code      string
+				category  paypalnvp.ErrorCategory
+				retryable bool
+				fault     paypalnvp.Fault
+			}{
+				{"10001", paypalnvp.CategoryInternal, true, paypalnvp.FaultPayPal},
+				{"10002", paypalnvp.CategoryAuthentication, false, paypalnvp.FaultCaller},
+				{"10321", paypalnvp.CategoryInsufficientFunds, false, paypalnvp.FaultCaller},
+				{"10305", paypalnvp.CategoryRestricted, false, paypalnvp.FaultCaller},
+				{"10307", paypalnvp.CategoryRestricted, false, paypalnvp.FaultCaller},
+				{"15005", paypalnvp.CategoryDeclined, false, paypalnvp.FaultCaller},
+				{"11210", paypalnvp.CategoryMassPay, false, paypalnvp.FaultCaller},
+				{"99999", paypalnvp.CategoryUnknown, false, paypalnvp.FaultUnknown},
+			}
+
+			for _, test := range tests {
+				responseError := paypalnvp.ResponseError{Code: test.code}
+
+				if responseError.Category() != test.category || responseError.Retryable() != test.retryable || responseError.Fault() != test.fault {
+					t.Fatalf(
+						"Expected %s to be %s, %t, %s, got: %s, %t, %s",
+						test.code,
+						test.category,
+						test.retryable,
+						test.fault,
+						responseError.Category(),
+						responseError.Retryable(),
+						responseError.Fault(),
+					)
+				}
+			}
+		})
+	})
 }
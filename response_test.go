@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/vidsy/go-paypalnvp"
 )
@@ -35,6 +36,29 @@ func TestResponse(t *testing.T) {
 			if response.Build != "000000" {
 				t.Fatalf("Expected Build == '78', got: '%s'", response.Build)
 			}
+
+			expectedTimeStamp := time.Date(2011, 11, 15, 20, 27, 2, 0, time.UTC)
+			if !response.TimeStamp.Equal(expectedTimeStamp) {
+				t.Fatalf("Expected TimeStamp == '%s', got: '%s'", expectedTimeStamp, response.TimeStamp)
+			}
+		})
+
+		t.Run("ReturnsErrorOnInvalidField", func(t *testing.T) {
+			data := `TIMESTAMP=yesterday&ACK=Success`
+			httpResponse := &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+				StatusCode: 200,
+			}
+
+			response, err := paypalnvp.NewResponse(httpResponse)
+
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+
+			if response == nil || response.Acknowledgement != paypalnvp.AckSuccess {
+				t.Fatalf("Expected remaining fields to be decoded, got: %+v", response)
+			}
 		})
 
 		t.Run("ErrorsMappedCorrectly", func(t *testing.T) {
@@ -102,6 +126,61 @@ func TestResponse(t *testing.T) {
 			}
 		})
 
+		t.Run("WithWarnings", func(t *testing.T) {
+			data := `ACK=SuccessWithWarning&L_ERRORCODE0=11607&L_SHORTMESSAGE0=Duplicate%20Request&L_SEVERITYCODE0=Warning`
+			httpResponse := &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+				StatusCode: 200,
+			}
+
+			response, _ := paypalnvp.NewResponse(httpResponse)
+
+			if !response.Successful() || !response.IsWarning() || response.IsFailure() {
+				t.Fatalf("Expected successful response with warnings, got: %t, %t, %t", response.Successful(), response.IsWarning(), response.IsFailure())
+			}
+
+			if response.ErrorCount() != 0 || len(response.Warnings) != 1 || response.Warnings[0].Code != "11607" {
+				t.Fatalf("Expected 1 warning and no errors, got: %+v, %+v", response.Warnings, response.Errors)
+			}
+		})
+
+		t.Run("NotWithFailureAck", func(t *testing.T) {
+			data := `ACK=Failure`
+			httpResponse := &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+				StatusCode: 200,
+			}
+
+			response, _ := paypalnvp.NewResponse(httpResponse)
+
+			if response.Successful() || !response.IsFailure() {
+				t.Fatalf("Expected failed response, got: %t, %t", response.Successful(), response.IsFailure())
+			}
+		})
+
+		t.Run("NotWithPartialSuccess", func(t *testing.T) {
+			data := `ACK=PartialSuccess`
+			httpResponse := &http.Response{
+				Body:       ioutil.NopCloser(bytes.NewBufferString(data)),
+				StatusCode: 200,
+			}
+
+			response, _ := paypalnvp.NewResponse(httpResponse)
+
+			if response.Successful() || response.IsFailure() {
+				t.Fatalf("Expected neither success nor failure, got: %t, %t", response.Successful(), response.IsFailure())
+			}
+		})
+
+		t.Run("WithoutHTTPResponse", func(t *testing.T) {
+			success := paypalnvp.Response{Acknowledgement: paypalnvp.AckSuccess}
+			failure := paypalnvp.Response{Acknowledgement: paypalnvp.AckFailure}
+
+			if !success.Successful() || success.IsFailure() || failure.Successful() || !failure.IsFailure() {
+				t.Fatalf("Expected ACK to decide without an HTTP response, got: %t, %t", success.Successful(), failure.IsFailure())
+			}
+		})
+
 		t.Run("NotWithErrors", func(t *testing.T) {
 			data := `L_ERRORCODE0=15005&L_SHORTMESSAGE0=Processor%20Decline&L_LONGMESSAGE0=This%20transaction%20cannot%20be%20processed%2e&L_SEVERITYCODE0=Error&L_ERRORPARAMID0=ProcessorResponse&L_ERRORPARAMVALUE0=0051`
 			httpResponse := &http.Response{
@@ -117,6 +196,31 @@ func TestResponse(t *testing.T) {
 		})
 	})
 
+	t.Run("Acknowledgement", func(t *testing.T) {
+		t.Run("ClassifiesValues", func(t *testing.T) {
+			tests := []struct {
+				ack     paypalnvp.Acknowledgement
+				success bool
+				warning bool
+				failure bool
+			}{
+				{paypalnvp.AckSuccess, true, false, false},
+				{paypalnvp.AckSuccessWithWarning, true, true, false},
+				{paypalnvp.AckWarning, true, true, false},
+				{paypalnvp.AckFailure, false, false, true},
+				{paypalnvp.AckFailureWithWarning, false, true, true},
+				{paypalnvp.AckPartialSuccess, false, false, false},
+				{"", false, false, false},
+			}
+
+			for _, test := range tests {
+				if test.ack.IsSuccess() != test.success || test.ack.IsWarning() != test.warning || test.ack.IsFailure() != test.failure {
+					t.Fatalf("Expected '%s' to be %t, %t, %t, got: %t, %t, %t", test.ack, test.success, test.warning, test.failure, test.ack.IsSuccess(), test.ack.IsWarning(), test.ack.IsFailure())
+				}
+			}
+		})
+	})
+
 	t.Run(".ErrorCount", func(t *testing.T) {
 		t.Run("WithErrors", func(t *testing.T) {
 			data := `L_ERRORCODE0=15005&L_SHORTMESSAGE0=a&L_LONGMESSAGE0=b&L_SEVERITYCODE0=Error&L_ERRORPARAMID0=ProcessorResponse&L_ERRORPARAMVALUE0=0051&L_ERRORCODE1=15006&L_SHORTMESSAGE1=c&L_LONGMESSAGE0=d&L_SEVERITYCODE1=Error&L_ERRORPARAMID1=ProcessorResponse&L_ERRORPARAMVALUE1=0052`
@@ -0,0 +1,98 @@
+package paypalnvp
+
+import (
+	"errors"
+	"math/rand"
+	"net/url"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp/nvp"
+)
+
+const (
+	// IdempotencyKeyField NVP field carrying a caller supplied idempotency
+	// key, which makes any method safe to retry.
+	IdempotencyKeyField = "MSGSUBID"
+)
+
+type (
+	// RetryPolicy decides whether a failed attempt should be retried and
+	// how long to wait before doing so. attempt starts at 1, and either
+	// response or err is set, or both if the response could not be fully
+	// decoded. The client only consults the policy for
+	// requests that are safe to repeat.
+	RetryPolicy interface {
+		Retry(attempt int, response *Response, err error) (time.Duration, bool)
+	}
+
+	// ExponentialBackoff RetryPolicy retrying transport errors, 5xx status
+	// codes and retryable PayPal errors, see ResponseError.Retryable,
+	// doubling the delay between attempts.
+	ExponentialBackoff struct {
+		// MaxAttempts total number of attempts, including the first.
+		MaxAttempts int
+
+		// BaseDelay delay before the first retry.
+		BaseDelay time.Duration
+
+		// MaxDelay upper bound of the delay, no bound if zero.
+		MaxDelay time.Duration
+
+		// Jitter fraction of the delay, between 0 and 1, that is randomly
+		// removed from each delay.
+		Jitter float64
+	}
+)
+
+var safeMethods = map[string]bool{
+	"GetBalance":                true,
+	"GetExpressCheckoutDetails": true,
+	"GetTransactionDetails":     true,
+	"TransactionSearch":         true,
+}
+
+// Retry returns the delay before the next attempt if the attempt failed
+// transiently and attempts remain.
+func (eb ExponentialBackoff) Retry(attempt int, response *Response, err error) (time.Duration, bool) {
+	if attempt >= eb.MaxAttempts || !transientFailure(response, err) {
+		return 0, false
+	}
+
+	delay := eb.BaseDelay << uint(attempt-1)
+	if eb.MaxDelay > 0 && (delay > eb.MaxDelay || delay < eb.BaseDelay) {
+		delay = eb.MaxDelay
+	}
+
+	if eb.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * eb.Jitter * float64(delay))
+	}
+
+	return delay, true
+}
+
+func transientFailure(response *Response, err error) bool {
+	if err != nil {
+		return !decodeFailure(err)
+	}
+
+	if response.StatusCode >= 500 {
+		return true
+	}
+
+	for _, responseError := range response.Errors {
+		if responseError.Retryable() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func retryable(data url.Values) bool {
+	return safeMethods[data.Get("METHOD")] || data.Get(IdempotencyKeyField) != ""
+}
+
+func decodeFailure(err error) bool {
+	var decodeErr nvp.DecodeError
+	return errors.As(err, &decodeErr)
+}
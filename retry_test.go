@@ -0,0 +1,186 @@
+package paypalnvp_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+func NewFlakyClient(failures int, calls *int) MockClient {
+	return MockClient{
+		MockDo: func(request *http.Request) (*http.Response, error) {
+			*calls++
+			if *calls <= failures {
+				return nil, errors.New("Connection reset")
+			}
+
+			return NewMockResponse([]byte(`ACK=Success`))
+		},
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := paypalnvp.ExponentialBackoff{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	t.Run(".Execute", func(t *testing.T) {
+		t.Run("RetriesSafeMethods", func(t *testing.T) {
+			calls := 0
+			client, _ := paypalnvp.NewClient(NewFlakyClient(2, &calls), paypalnvp.Sandbox, "user", "password", "signature")
+			client.RetryPolicy = policy
+			payload := SerializedDataMock{
+				mockSerialize: func() (url.Values, error) {
+					return url.Values{"METHOD": {"GetBalance"}}, nil
+				},
+			}
+
+			response, err := client.Execute(payload)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if response.Attempts != 3 {
+				t.Fatalf("Expected Attempts to be 3, got: %d", response.Attempts)
+			}
+		})
+
+		t.Run("RetriesMethodsWithIdempotencyKey", func(t *testing.T) {
+			calls := 0
+			client, _ := paypalnvp.NewClient(NewFlakyClient(1, &calls), paypalnvp.Sandbox, "user", "password", "signature")
+			client.RetryPolicy = policy
+			payload := SerializedDataMock{
+				mockSerialize: func() (url.Values, error) {
+					return url.Values{"METHOD": {"RefundTransaction"}, "MSGSUBID": {"refund-1"}}, nil
+				},
+			}
+
+			response, err := client.Execute(payload)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if response.Attempts != 2 {
+				t.Fatalf("Expected Attempts to be 2, got: %d", response.Attempts)
+			}
+		})
+
+		t.Run("DoesNotRetryUnsafeMethods", func(t *testing.T) {
+			calls := 0
+			client, _ := paypalnvp.NewClient(NewFlakyClient(1, &calls), paypalnvp.Sandbox, "user", "password", "signature")
+			client.RetryPolicy = policy
+			payload := SerializedDataMock{
+				mockSerialize: func() (url.Values, error) {
+					return url.Values{"METHOD": {"MassPay"}}, nil
+				},
+			}
+
+			_, err := client.Execute(payload)
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+
+			if calls != 1 {
+				t.Fatalf("Expected 1 call, got: %d", calls)
+			}
+		})
+
+		t.Run("DoesNotRetryDecodeErrors", func(t *testing.T) {
+			calls := 0
+			mockClient := MockClient{
+				MockDo: func(request *http.Request) (*http.Response, error) {
+					calls++
+					return NewMockResponse([]byte(`ACK=Success&TIMESTAMP=yesterday`))
+				},
+			}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.RetryPolicy = policy
+			payload := SerializedDataMock{
+				mockSerialize: func() (url.Values, error) {
+					return url.Values{"METHOD": {"GetBalance"}}, nil
+				},
+			}
+
+			response, err := client.Execute(payload)
+			if err == nil || response == nil || response.Acknowledgement != paypalnvp.AckSuccess {
+				t.Fatalf("Expected partly decoded response and an error, got: %v, %v", response, err)
+			}
+
+			if calls != 1 {
+				t.Fatalf("Expected 1 call, got: %d", calls)
+			}
+		})
+
+		t.Run("StopsAfterMaxAttempts", func(t *testing.T) {
+			calls := 0
+			client, _ := paypalnvp.NewClient(NewFlakyClient(5, &calls), paypalnvp.Sandbox, "user", "password", "signature")
+			client.RetryPolicy = policy
+			payload := SerializedDataMock{
+				mockSerialize: func() (url.Values, error) {
+					return url.Values{"METHOD": {"GetBalance"}}, nil
+				},
+			}
+
+			_, err := client.Execute(payload)
+			if err == nil {
+				t.Fatalf("Expected an error, got: %v", err)
+			}
+
+			if calls != 3 {
+				t.Fatalf("Expected 3 calls, got: %d", calls)
+			}
+		})
+	})
+
+	t.Run("ExponentialBackoff", func(t *testing.T) {
+		t.Run("DoublesDelayUpToMaxDelay", func(t *testing.T) {
+			backoff := paypalnvp.ExponentialBackoff{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 3 * time.Second}
+			expectedDelays := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+
+			for i, expectedDelay := range expectedDelays {
+				delay, retry := backoff.Retry(i+1, nil, errors.New("Connection reset"))
+				if !retry || delay != expectedDelay {
+					t.Fatalf("Expected attempt %d to retry after %s, got: %t, %s", i+1, expectedDelay, retry, delay)
+				}
+			}
+		})
+
+		t.Run("RetriesInternalErrors", func(t *testing.T) {
+			backoff := paypalnvp.ExponentialBackoff{MaxAttempts: 2}
+			response := &paypalnvp.Response{
+				Response: &http.Response{StatusCode: 200},
+				Errors:   []paypalnvp.ResponseError{{Code: "10001"}},
+			}
+
+			if _, retry := backoff.Retry(1, response, nil); !retry {
+				t.Fatalf("Expected retry to be true, got: %t", retry)
+			}
+		})
+
+		t.Run("RetriesRetryableMassPayErrors", func(t *testing.T) {
+			backoff := paypalnvp.ExponentialBackoff{MaxAttempts: 2}
+			response := &paypalnvp.Response{
+				Response: &http.Response{StatusCode: 200},
+				Errors:   []paypalnvp.ResponseError{{Code: "10309"}},
+			}
+
+			if _, retry := backoff.Retry(1, response, nil); !retry {
+				t.Fatalf("Expected retry to be true, got: %t", retry)
+			}
+		})
+
+		t.Run("DoesNotRetryValidationErrors", func(t *testing.T) {
+			backoff := paypalnvp.ExponentialBackoff{MaxAttempts: 2}
+			response := &paypalnvp.Response{
+				Response: &http.Response{StatusCode: 200},
+				Errors:   []paypalnvp.ResponseError{{Code: "10004"}},
+			}
+
+			if _, retry := backoff.Retry(1, response, nil); retry {
+				t.Fatalf("Expected retry to be false, got: %t", retry)
+			}
+		})
+	})
+}
@@ -0,0 +1,103 @@
+package paypalnvp
+
+import (
+	"context"
+)
+
+const (
+	// SpanName name of the span covering each call made by Execute.
+	SpanName = "paypalnvp.Execute"
+
+	// AttributeMethod span attribute holding the NVP method.
+	AttributeMethod = "paypalnvp.method"
+
+	// AttributeEnvironment span attribute holding the environment.
+	AttributeEnvironment = "paypalnvp.environment"
+
+	// AttributeAcknowledgement span attribute holding the ACK.
+	AttributeAcknowledgement = "paypalnvp.ack"
+
+	// AttributeCorrelationID span attribute holding the CORRELATIONID.
+	AttributeCorrelationID = "paypalnvp.correlation_id"
+
+	// AttributeAttempts span attribute holding the number of attempts
+	// made, including retries.
+	AttributeAttempts = "paypalnvp.attempts"
+
+	// AttributeErrorCodes span attribute holding the codes of the errors
+	// PayPal returned, as a []string.
+	AttributeErrorCodes = "paypalnvp.error_codes"
+
+	// AttributeHTTPStatusCode span attribute holding the HTTP status code
+	// of the response.
+	AttributeHTTPStatusCode = "http.response.status_code"
+)
+
+type (
+	// Tracer starts spans, e.g. by adapting an OpenTelemetry tracer. The
+	// span should be a child of any span in ctx, and the returned context
+	// should carry the new span so requests made with it are propagated.
+	Tracer interface {
+		Start(ctx context.Context, name string) (context.Context, Span)
+	}
+
+	// Span started by a Tracer.
+	Span interface {
+		// SetAttribute sets an attribute, the value is a string, int or
+		// []string.
+		SetAttribute(key string, value interface{})
+
+		// RecordError records the error and marks the span as failed.
+		// Responses PayPal reports as failures are recorded as an
+		// *APIError, even if TypedErrors is not set.
+		RecordError(err error)
+
+		// End completes the span.
+		End()
+	}
+
+	noopSpan struct{}
+)
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+
+func (noopSpan) RecordError(err error) {}
+
+func (noopSpan) End() {}
+
+func (c Client) startSpan(ctx context.Context) (context.Context, Span) {
+	if c.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := c.Tracer.Start(ctx, SpanName)
+	span.SetAttribute(AttributeEnvironment, string(c.environment))
+
+	return ctx, span
+}
+
+func (c Client) endSpan(span Span, response *Response, err error) {
+	if response != nil {
+		span.SetAttribute(AttributeAcknowledgement, string(response.Acknowledgement))
+		span.SetAttribute(AttributeCorrelationID, response.CorrelationID)
+		span.SetAttribute(AttributeAttempts, response.Attempts)
+
+		if statusCode := response.statusCode(); statusCode != 0 {
+			span.SetAttribute(AttributeHTTPStatusCode, statusCode)
+		}
+
+		if len(response.Errors) > 0 {
+			span.SetAttribute(AttributeErrorCodes, responseErrorCodes(response.Errors))
+		}
+	}
+
+	if err == nil && response != nil && response.IsFailure() {
+		err = &APIError{Response: response}
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
@@ -0,0 +1,127 @@
+package paypalnvp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/vidsy/go-paypalnvp"
+)
+
+type (
+	spanKey struct{}
+
+	MockTracer struct {
+		Spans []*MockSpan
+	}
+
+	MockSpan struct {
+		Name       string
+		Parent     *MockSpan
+		Attributes map[string]interface{}
+		Errors     []error
+		Ended      bool
+	}
+)
+
+func (mt *MockTracer) Start(ctx context.Context, name string) (context.Context, paypalnvp.Span) {
+	parent, _ := ctx.Value(spanKey{}).(*MockSpan)
+	span := &MockSpan{Name: name, Parent: parent, Attributes: map[string]interface{}{}}
+	mt.Spans = append(mt.Spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (ms *MockSpan) SetAttribute(key string, value interface{}) {
+	ms.Attributes[key] = value
+}
+
+func (ms *MockSpan) RecordError(err error) {
+	ms.Errors = append(ms.Errors, err)
+}
+
+func (ms *MockSpan) End() {
+	ms.Ended = true
+}
+
+func TestTracing(t *testing.T) {
+	massPay := SerializedDataMock{
+		mockSerialize: func() (url.Values, error) {
+			return url.Values{"METHOD": {"MassPay"}}, nil
+		},
+	}
+
+	t.Run(".Execute", func(t *testing.T) {
+		t.Run("CreatesSpanWithAttributes", func(t *testing.T) {
+			parent := &MockSpan{Name: "parent"}
+			var requestSpan *MockSpan
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					requestSpan, _ = req.Context().Value(spanKey{}).(*MockSpan)
+					return NewMockResponse([]byte(`ACK=Failure&CORRELATIONID=5be53331d9700&L_ERRORCODE0=10321&L_SEVERITYCODE0=Error`))
+				},
+			}
+			tracer := &MockTracer{}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Live, "user", "password", "signature")
+			client.Tracer = tracer
+
+			client.ExecuteContext(context.WithValue(context.Background(), spanKey{}, parent), massPay)
+
+			if len(tracer.Spans) != 1 {
+				t.Fatalf("Expected 1 span, got: %d", len(tracer.Spans))
+			}
+
+			span := tracer.Spans[0]
+			if span.Name != paypalnvp.SpanName || span.Parent != parent || !span.Ended {
+				t.Fatalf("Expected ended child span of parent, got: %+v", span)
+			}
+
+			if requestSpan != span {
+				t.Fatalf("Expected request context to carry the span, got: %+v", requestSpan)
+			}
+
+			expected := map[string]interface{}{
+				paypalnvp.AttributeMethod:          "MassPay",
+				paypalnvp.AttributeEnvironment:     "live",
+				paypalnvp.AttributeAcknowledgement: "Failure",
+				paypalnvp.AttributeCorrelationID:   "5be53331d9700",
+				paypalnvp.AttributeAttempts:        1,
+				paypalnvp.AttributeHTTPStatusCode:  200,
+				paypalnvp.AttributeErrorCodes:      []string{"10321"},
+			}
+			if !reflect.DeepEqual(span.Attributes, expected) {
+				t.Fatalf("Expected attributes %v, got: %v", expected, span.Attributes)
+			}
+
+			var apiError *paypalnvp.APIError
+			if len(span.Errors) != 1 || !errors.As(span.Errors[0], &apiError) {
+				t.Fatalf("Expected failure to be recorded as an *APIError, got: %v", span.Errors)
+			}
+		})
+
+		t.Run("RecordsTransportErrors", func(t *testing.T) {
+			mockClient := MockClient{
+				MockDo: func(req *http.Request) (*http.Response, error) {
+					return nil, errors.New("Connection reset")
+				},
+			}
+			tracer := &MockTracer{}
+			client, _ := paypalnvp.NewClient(mockClient, paypalnvp.Sandbox, "user", "password", "signature")
+			client.Tracer = tracer
+
+			client.Execute(massPay)
+
+			span := tracer.Spans[0]
+			if len(span.Errors) != 1 || span.Errors[0].Error() != "Connection reset" || !span.Ended {
+				t.Fatalf("Expected transport error to be recorded, got: %+v", span)
+			}
+
+			if _, ok := span.Attributes[paypalnvp.AttributeAcknowledgement]; ok {
+				t.Fatalf("Expected no ACK attribute, got: %v", span.Attributes)
+			}
+		})
+	})
+}